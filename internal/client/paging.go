@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+const (
+	defaultPageSize    = 50
+	defaultConcurrency = 4
+)
+
+// PageFetcher fetches a single page of items starting at offset. It returns the
+// items on that page and the total number of items available.
+type PageFetcher[T any] func(ctx context.Context, offset, limit int) ([]T, int, error)
+
+// FetchAllOptions controls how FetchAll walks a paginated endpoint.
+type FetchAllOptions struct {
+	// PageSize is the number of items requested per page (default: 50).
+	PageSize int
+	// Concurrency is the maximum number of pages fetched at once (default: 4).
+	Concurrency int
+	// MaxItems caps the number of items returned. Zero means no cap.
+	MaxItems int
+}
+
+// FetchAll fetches every page of a paginated endpoint and returns the items in
+// order. The first page is fetched on its own to learn the total, after which
+// the remaining pages are fetched concurrently. Fetching stops at the first
+// error or when ctx is cancelled.
+func FetchAll[T any](ctx context.Context, fetch PageFetcher[T], opts FetchAllOptions) ([]T, error) {
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	firstLimit := pageSize
+	if opts.MaxItems > 0 && opts.MaxItems < firstLimit {
+		firstLimit = opts.MaxItems
+	}
+
+	first, total, err := fetch(ctx, 0, firstLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page at offset 0: %w", err)
+	}
+
+	wanted := total
+	if opts.MaxItems > 0 && opts.MaxItems < wanted {
+		wanted = opts.MaxItems
+	}
+
+	if len(first) >= wanted || len(first) == 0 {
+		return capItems(first, opts.MaxItems), nil
+	}
+
+	var offsets []int
+	for offset := len(first); offset < wanted; offset += pageSize {
+		offsets = append(offsets, offset)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pages := make([][]T, len(offsets))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	for i, offset := range offsets {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i, offset int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			limit := pageSize
+			if offset+limit > wanted {
+				limit = wanted - offset
+			}
+
+			items, _, err := fetch(ctx, offset, limit)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("failed to fetch page at offset %d: %w", offset, err)
+					cancel()
+				})
+				return
+			}
+
+			pages[i] = items
+		}(i, offset)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	items := first
+	for _, page := range pages {
+		items = append(items, page...)
+	}
+
+	return capItems(items, opts.MaxItems), nil
+}
+
+func capItems[T any](items []T, maxItems int) []T {
+	if maxItems > 0 && len(items) > maxItems {
+		return items[:maxItems]
+	}
+	return items
+}
+
+// PlaylistItemsFetcher returns a PageFetcher over the items of a playlist.
+func PlaylistItemsFetcher(spotifyClient *spotify.Client, playlistID spotify.ID) PageFetcher[spotify.PlaylistItem] {
+	return func(ctx context.Context, offset, limit int) ([]spotify.PlaylistItem, int, error) {
+		if limit > 100 {
+			limit = 100
+		}
+
+		page, err := spotifyClient.GetPlaylistItems(ctx, playlistID, spotify.Limit(limit), spotify.Offset(offset))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return page.Items, int(page.Total), nil
+	}
+}
+
+// UserPlaylistsFetcher returns a PageFetcher over the playlists of a user.
+func UserPlaylistsFetcher(spotifyClient *spotify.Client, userID string) PageFetcher[spotify.SimplePlaylist] {
+	return func(ctx context.Context, offset, limit int) ([]spotify.SimplePlaylist, int, error) {
+		if limit > 50 {
+			limit = 50
+		}
+
+		page, err := spotifyClient.GetPlaylistsForUser(ctx, userID, spotify.Limit(limit), spotify.Offset(offset))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return page.Playlists, int(page.Total), nil
+	}
+}
+
+// AlbumTracksFetcher returns a PageFetcher over the tracks of an album.
+func AlbumTracksFetcher(spotifyClient *spotify.Client, albumID spotify.ID) PageFetcher[spotify.SimpleTrack] {
+	return func(ctx context.Context, offset, limit int) ([]spotify.SimpleTrack, int, error) {
+		if limit > 50 {
+			limit = 50
+		}
+
+		page, err := spotifyClient.GetAlbumTracks(ctx, albumID, spotify.Limit(limit), spotify.Offset(offset))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return page.Tracks, int(page.Total), nil
+	}
+}
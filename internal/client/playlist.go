@@ -0,0 +1,90 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+const apiBaseURL = "https://api.spotify.com/v1/"
+
+// ChangePlaylistCollaborative makes a playlist collaborative or not. The SDK
+// has no call for this. Spotify only allows private playlists to be
+// collaborative, so making one collaborative also makes it private.
+func ChangePlaylistCollaborative(ctx context.Context, playlistID spotify.ID, collaborative bool) error {
+	fields := map[string]bool{"collaborative": collaborative}
+	if collaborative {
+		fields["public"] = false
+	}
+
+	return apiRequest(ctx, http.MethodPut, "playlists/"+string(playlistID), fields, nil)
+}
+
+// AddItemsToPlaylist adds up to 100 track or episode URIs to a playlist,
+// inserted before the zero-based position or appended if position is
+// negative. Unlike the SDK's AddTracksToPlaylist it takes a position and
+// episodes.
+func AddItemsToPlaylist(ctx context.Context, playlistID spotify.ID, position int, uris ...spotify.URI) (string, error) {
+	body := struct {
+		URIs     []spotify.URI `json:"uris"`
+		Position *int          `json:"position,omitempty"`
+	}{URIs: uris}
+	if position >= 0 {
+		body.Position = &position
+	}
+
+	var result struct {
+		SnapshotID string `json:"snapshot_id"`
+	}
+	if err := apiRequest(ctx, http.MethodPost, "playlists/"+string(playlistID)+"/tracks", body, &result); err != nil {
+		return "", err
+	}
+
+	return result.SnapshotID, nil
+}
+
+// apiRequest sends body as JSON to a Web API endpoint with the authenticated
+// client and decodes the response into result, if given. Error responses are
+// returned as spotify.Error, like the SDK does.
+func apiRequest(ctx context.Context, method, path string, body, result any) error {
+	if authenticatedHTTPClient == nil {
+		return fmt.Errorf("not authenticated")
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, apiBaseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiError struct {
+			Error spotify.Error `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&apiError); err != nil || apiError.Error.Message == "" {
+			return spotify.Error{Message: resp.Status, Status: resp.StatusCode}
+		}
+		return apiError.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -21,6 +21,10 @@ var (
 	// AuthenticatedSpotifyClient Client with playback permissions
 	AuthenticatedSpotifyClient *spotify.Client
 
+	// authenticatedHTTPClient is the HTTP client behind
+	// AuthenticatedSpotifyClient, for endpoints the SDK doesn't cover.
+	authenticatedHTTPClient *http.Client
+
 	playbackAuth  *spotifyauth.Authenticator
 	authComplete  = make(chan struct{})
 	serverRunning bool
@@ -48,6 +52,8 @@ func InstantiateSpotifyClient(ctx context.Context) {
 			spotifyauth.ScopePlaylistModifyPrivate,
 			spotifyauth.ScopePlaylistReadCollaborative,
 			spotifyauth.ScopePlaylistReadPrivate,
+			spotifyauth.ScopeUserReadRecentlyPlayed,
+			spotifyauth.ScopeImageUpload,
 		),
 		spotifyauth.WithClientID(os.Getenv("SPOTIFY_CLIENT_ID")),
 		spotifyauth.WithClientSecret(os.Getenv("SPOTIFY_CLIENT_SECRET")),
@@ -105,7 +111,8 @@ func completeAuth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	AuthenticatedSpotifyClient = spotify.New(playbackAuth.Client(r.Context(), tok))
+	authenticatedHTTPClient = playbackAuth.Client(r.Context(), tok)
+	AuthenticatedSpotifyClient = spotify.New(authenticatedHTTPClient)
 
 	w.Header().Set("Content-Type", "text/html")
 	html := `
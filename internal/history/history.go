@@ -0,0 +1,248 @@
+// Package history keeps a local record of what has been listened to, so that
+// listening history reaches further back than Spotify's last 50 plays.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"spotify-mcp/internal/matching"
+	"spotify-mcp/internal/storage"
+)
+
+const (
+	historyFile = "history.jsonl"
+	// importedFile holds listens imported from Spotify's streaming history
+	// export, kept apart from the recorder's events.
+	importedFile = "streaming_history.jsonl"
+)
+
+// Event types. A track produces a play event when it starts and a complete or
+// skip event when it ends; pause and resume are recorded in between.
+const (
+	EventPlay     = "play"
+	EventPause    = "pause"
+	EventResume   = "resume"
+	EventSkip     = "skip"
+	EventComplete = "complete"
+)
+
+// Event sources.
+const (
+	SourceRecorder = "recorder"
+	SourceImport   = "streaming_history"
+)
+
+var fileMutex sync.Mutex
+
+// Event is one line of the history file.
+type Event struct {
+	Time       time.Time `json:"time"`
+	Type       string    `json:"type"`
+	TrackID    string    `json:"track_id,omitempty"`
+	TrackURI   string    `json:"track_uri,omitempty"`
+	Track      string    `json:"track"`
+	Artists    []string  `json:"artists,omitempty"`
+	Album      string    `json:"album,omitempty"`
+	ContextURI string    `json:"context_uri,omitempty"`
+	Device     string    `json:"device,omitempty"`
+	// DurationMs is the length of the track.
+	DurationMs int `json:"duration_ms,omitempty"`
+	// PlayedMs is how far into the track playback got, for skip and
+	// complete events.
+	PlayedMs int `json:"played_ms,omitempty"`
+	// StartedAt is when the track started, for skip and complete events.
+	StartedAt time.Time `json:"started_at,omitempty"`
+	Source    string    `json:"source,omitempty"`
+}
+
+// IsListen reports whether the event marks the end of a listened track.
+func (e Event) IsListen() bool {
+	return e.Type == EventSkip || e.Type == EventComplete
+}
+
+// Filter selects events from the history.
+type Filter struct {
+	From time.Time
+	To   time.Time
+	// Artist and Track match loosely, ignoring case, accents and punctuation.
+	Artist string
+	Track  string
+	// ListensOnly keeps only skip and complete events.
+	ListensOnly bool
+}
+
+// Matches reports whether event passes the filter.
+func (f Filter) Matches(event Event) bool {
+	if !f.From.IsZero() && event.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && event.Time.After(f.To) {
+		return false
+	}
+	if f.ListensOnly && !event.IsListen() {
+		return false
+	}
+	if f.Track != "" && !strings.Contains(matching.Normalize(event.Track), matching.Normalize(f.Track)) {
+		return false
+	}
+	if f.Artist != "" {
+		want := matching.Normalize(f.Artist)
+		found := false
+		for _, artist := range event.Artists {
+			if strings.Contains(matching.Normalize(artist), want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Path returns the location of the history file.
+func Path() (string, error) {
+	return storage.Path(historyFile)
+}
+
+// ImportedPath returns the location of the imported streaming history.
+func ImportedPath() (string, error) {
+	return storage.Path(importedFile)
+}
+
+// Append adds events to the end of the history file.
+func Append(events ...Event) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	return appendEvents(path, events)
+}
+
+func appendEvents(path string, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return file.Close()
+}
+
+// Read calls visit for every recorded and imported event matching filter,
+// oldest first. Where the imported history covers a period, recorded events
+// from that period are left out so listens aren't counted twice.
+func Read(filter Filter, visit func(Event)) error {
+	importedPath, err := ImportedPath()
+	if err != nil {
+		return err
+	}
+
+	var imported []Event
+	var importedFrom, importedTo time.Time
+	err = readEvents(importedPath, func(event Event) {
+		if importedFrom.IsZero() || event.Time.Before(importedFrom) {
+			importedFrom = event.Time
+		}
+		if event.Time.After(importedTo) {
+			importedTo = event.Time
+		}
+		if filter.Matches(event) {
+			imported = append(imported, event)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	recordedPath, err := Path()
+	if err != nil {
+		return err
+	}
+
+	var recorded []Event
+	err = readEvents(recordedPath, func(event Event) {
+		if !importedFrom.IsZero() && !event.Time.Before(importedFrom) && !event.Time.After(importedTo) {
+			return
+		}
+		if filter.Matches(event) {
+			recorded = append(recorded, event)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	events := append(imported, recorded...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	for _, event := range events {
+		visit(event)
+	}
+
+	return nil
+}
+
+// readEvents calls visit for every event in the JSONL file at path, in file
+// order. Lines that can't be decoded are skipped.
+func readEvents(path string, visit func(Event)) error {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		visit(event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// streamingHistoryPattern matches the audio files of Spotify's extended
+// streaming history export.
+const streamingHistoryPattern = "Streaming_History_Audio_*.json"
+
+// streamingHistoryRecord is one play in Spotify's extended streaming history
+// export. Podcast episodes have no track URI and are not imported.
+type streamingHistoryRecord struct {
+	Timestamp string `json:"ts"`
+	MsPlayed  int    `json:"ms_played"`
+	Track     string `json:"master_metadata_track_name"`
+	Artist    string `json:"master_metadata_album_artist_name"`
+	Album     string `json:"master_metadata_album_album_name"`
+	TrackURI  string `json:"spotify_track_uri"`
+	Platform  string `json:"platform"`
+	ReasonEnd string `json:"reason_end"`
+	Skipped   *bool  `json:"skipped"`
+}
+
+// ImportResult summarizes an import.
+type ImportResult struct {
+	Files      []string
+	Imported   int
+	Duplicates int
+	// Skipped counts podcast episodes and records without a track.
+	Skipped int
+	From    time.Time
+	To      time.Time
+}
+
+// ImportStreamingHistory reads Streaming_History_Audio_*.json files into the
+// local history. Each path may be a file or a directory of export files.
+// Plays already imported are skipped, so importing again is safe.
+func ImportStreamingHistory(paths []string) (ImportResult, error) {
+	var result ImportResult
+
+	files, err := streamingHistoryFiles(paths)
+	if err != nil {
+		return result, err
+	}
+	if len(files) == 0 {
+		return result, fmt.Errorf("no %s files found", streamingHistoryPattern)
+	}
+	result.Files = files
+
+	importedPath, err := ImportedPath()
+	if err != nil {
+		return result, err
+	}
+
+	seen := map[string]bool{}
+	err = readEvents(importedPath, func(event Event) {
+		seen[importKey(event)] = true
+	})
+	if err != nil {
+		return result, err
+	}
+
+	var events []Event
+	for _, file := range files {
+		records, err := readStreamingHistoryFile(file)
+		if err != nil {
+			return result, err
+		}
+
+		for _, record := range records {
+			event, ok := record.event()
+			if !ok {
+				result.Skipped++
+				continue
+			}
+
+			key := importKey(event)
+			if seen[key] {
+				result.Duplicates++
+				continue
+			}
+			seen[key] = true
+
+			events = append(events, event)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	if err := appendEvents(importedPath, events); err != nil {
+		return result, err
+	}
+
+	result.Imported = len(events)
+	if len(events) > 0 {
+		result.From = events[0].Time
+		result.To = events[len(events)-1].Time
+	}
+
+	return result, nil
+}
+
+func streamingHistoryFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(path, streamingHistoryPattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func readStreamingHistoryFile(path string) ([]streamingHistoryRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var records []streamingHistoryRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode %s, expected a streaming history export: %w", path, err)
+	}
+
+	return records, nil
+}
+
+// event converts the record into a listen. The export's timestamp is when
+// playback of the track ended.
+func (r streamingHistoryRecord) event() (Event, bool) {
+	if r.TrackURI == "" || r.Track == "" {
+		return Event{}, false
+	}
+
+	ended, err := time.Parse(time.RFC3339, r.Timestamp)
+	if err != nil {
+		return Event{}, false
+	}
+
+	eventType := EventComplete
+	if (r.Skipped != nil && *r.Skipped) || r.ReasonEnd == "fwdbtn" || r.ReasonEnd == "backbtn" {
+		eventType = EventSkip
+	}
+
+	var artists []string
+	if r.Artist != "" {
+		artists = []string{r.Artist}
+	}
+
+	return Event{
+		Time:      ended,
+		Type:      eventType,
+		TrackID:   strings.TrimPrefix(r.TrackURI, "spotify:track:"),
+		TrackURI:  r.TrackURI,
+		Track:     r.Track,
+		Artists:   artists,
+		Album:     r.Album,
+		Device:    r.Platform,
+		PlayedMs:  r.MsPlayed,
+		StartedAt: ended.Add(-time.Duration(r.MsPlayed) * time.Millisecond),
+		Source:    SourceImport,
+	}, true
+}
+
+func importKey(event Event) string {
+	return event.Time.UTC().Format(time.RFC3339) + " " + event.TrackURI
+}
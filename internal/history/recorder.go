@@ -0,0 +1,247 @@
+package history
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+)
+
+const (
+	recorderEnv      = "SPOTIFY_HISTORY_RECORDER"
+	pollIntervalEnv  = "SPOTIFY_HISTORY_POLL_SECONDS"
+	defaultPollDelay = 15 * time.Second
+	// endTolerance is how close to the end of a track playback must get for
+	// it to count as completed rather than skipped. It covers the time a
+	// track may finish between polls.
+	endTolerance = 5 * time.Second
+)
+
+var (
+	recorderMutex  sync.Mutex
+	recorderCancel context.CancelFunc
+	recorderWg     sync.WaitGroup
+)
+
+// RecorderEnabled reports whether SPOTIFY_HISTORY_RECORDER turns the
+// recorder on.
+func RecorderEnabled() bool {
+	enabled, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(recorderEnv)))
+	return enabled
+}
+
+func pollInterval() time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(os.Getenv(pollIntervalEnv)))
+	if err != nil || seconds <= 0 {
+		return defaultPollDelay
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// StartRecorder polls playback in the background and records listening events
+// until ctx is cancelled or StopRecorder is called.
+func StartRecorder(ctx context.Context) {
+	recorderMutex.Lock()
+	defer recorderMutex.Unlock()
+
+	if recorderCancel != nil {
+		return
+	}
+
+	ctx, recorderCancel = context.WithCancel(ctx)
+
+	recorderWg.Add(1)
+	go func() {
+		defer recorderWg.Done()
+		record(ctx, pollInterval())
+	}()
+
+	log.Printf("Recording listening history every %s", pollInterval())
+}
+
+// StopRecorder stops the recorder and waits for it to exit.
+func StopRecorder() {
+	recorderMutex.Lock()
+	cancel := recorderCancel
+	recorderCancel = nil
+	recorderMutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	recorderWg.Wait()
+}
+
+// playbackSnapshot is what the recorder saw at one poll.
+type playbackSnapshot struct {
+	at         time.Time
+	track      *spotify.FullTrack
+	playing    bool
+	progressMs int
+	contextURI string
+	device     string
+	startedAt  time.Time
+}
+
+func record(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *playbackSnapshot
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !client.IsPlaybackAuthenticated() {
+			continue
+		}
+
+		state, err := client.AuthenticatedSpotifyClient.PlayerState(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("History recorder failed to get playback state: %v", err)
+			}
+			continue
+		}
+
+		current := snapshotFromState(state, time.Now())
+		events := compareSnapshots(last, current)
+		if err := Append(events...); err != nil {
+			log.Printf("History recorder failed to save events: %v", err)
+		}
+
+		sameTrack := current != nil && last != nil && current.track.ID == last.track.ID
+		switch {
+		case sameTrack && !restarted(last, current):
+			current.startedAt = last.startedAt
+		case current != nil && !current.playing && !sameTrack:
+			// A track that is paused before it was ever seen playing is
+			// recorded from when it starts.
+			current = nil
+		}
+		last = current
+	}
+}
+
+func snapshotFromState(state *spotify.PlayerState, now time.Time) *playbackSnapshot {
+	if state == nil || state.Item == nil || state.Item.ID == "" {
+		return nil
+	}
+
+	snapshot := &playbackSnapshot{
+		at:         now,
+		track:      state.Item,
+		playing:    state.Playing,
+		progressMs: int(state.Progress),
+		device:     state.Device.Name,
+		startedAt:  now.Add(-time.Duration(state.Progress) * time.Millisecond),
+	}
+	if state.PlaybackContext.URI != "" {
+		snapshot.contextURI = string(state.PlaybackContext.URI)
+	}
+
+	return snapshot
+}
+
+// compareSnapshots works out what happened between two polls.
+func compareSnapshots(last, current *playbackSnapshot) []Event {
+	switch {
+	case last == nil && current == nil:
+		return nil
+	case last == nil:
+		if !current.playing {
+			return nil
+		}
+		return []Event{trackEvent(EventPlay, current, current.at)}
+	case current == nil:
+		// Playback stopped or moved to something that isn't a track.
+		return []Event{endEvent(last, current)}
+	case current.track.ID != last.track.ID:
+		events := []Event{endEvent(last, current)}
+		if current.playing {
+			events = append(events, trackEvent(EventPlay, current, current.at))
+		}
+		return events
+	case restarted(last, current):
+		return []Event{endEvent(last, current), trackEvent(EventPlay, current, current.at)}
+	case last.playing && !current.playing:
+		return []Event{trackEvent(EventPause, current, current.at)}
+	case !last.playing && current.playing:
+		return []Event{trackEvent(EventResume, current, current.at)}
+	default:
+		return nil
+	}
+}
+
+// restarted reports whether the same track started over, e.g. on repeat.
+func restarted(last, current *playbackSnapshot) bool {
+	return current.progressMs < last.progressMs && reachedEnd(last, current.at)
+}
+
+// estimatedProgress is how far into its track last will have got by now.
+func estimatedProgress(last *playbackSnapshot, now time.Time) int {
+	progress := last.progressMs
+	if last.playing {
+		progress += int(now.Sub(last.at).Milliseconds())
+	}
+	if progress > int(last.track.Duration) {
+		progress = int(last.track.Duration)
+	}
+	return progress
+}
+
+func reachedEnd(last *playbackSnapshot, now time.Time) bool {
+	return estimatedProgress(last, now) >= int(last.track.Duration)-int(endTolerance.Milliseconds())
+}
+
+// endEvent records the end of last's track, as completed when playback got
+// to the end and as skipped otherwise.
+func endEvent(last, current *playbackSnapshot) Event {
+	now := time.Now()
+	if current != nil {
+		now = current.at
+	}
+
+	eventType := EventSkip
+	if reachedEnd(last, now) {
+		eventType = EventComplete
+	}
+
+	event := trackEvent(eventType, last, now)
+	event.PlayedMs = estimatedProgress(last, now)
+	event.StartedAt = last.startedAt
+
+	return event
+}
+
+func trackEvent(eventType string, snapshot *playbackSnapshot, at time.Time) Event {
+	track := snapshot.track
+
+	artists := make([]string, len(track.Artists))
+	for i, artist := range track.Artists {
+		artists[i] = artist.Name
+	}
+
+	return Event{
+		Time:       at,
+		Type:       eventType,
+		TrackID:    string(track.ID),
+		TrackURI:   string(track.URI),
+		Track:      track.Name,
+		Artists:    artists,
+		Album:      track.Album.Name,
+		ContextURI: snapshot.contextURI,
+		Device:     snapshot.device,
+		DurationMs: int(track.Duration),
+		Source:     SourceRecorder,
+	}
+}
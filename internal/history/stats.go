@@ -0,0 +1,245 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// minStreamMs is how long a track must play to count as a stream, the
+	// same threshold Spotify uses.
+	minStreamMs = 30_000
+	// maxTrackIDsPerEntry is how many track IDs are kept for an artist or
+	// album.
+	maxTrackIDsPerEntry = 5
+)
+
+// Stats summarizes listens over a period.
+type Stats struct {
+	From time.Time
+	To   time.Time
+	// Listens counts every finished or skipped track, Streams those played
+	// for at least 30 seconds.
+	Listens int
+	Streams int
+	Skips   int
+	TotalMs int64
+	Tracks  []Ranked
+	Artists []Ranked
+	Albums  []Ranked
+	ByHour  [24]int64
+	ByDay   [7]int64
+	Days    int
+	Longest Streak
+	Current Streak
+	// Distinct is the number of different tracks streamed.
+	Distinct int
+}
+
+// SkipRate is the share of listens that were skipped.
+func (s Stats) SkipRate() float64 {
+	if s.Listens == 0 {
+		return 0
+	}
+	return float64(s.Skips) / float64(s.Listens)
+}
+
+// Ranked is a track, artist or album with its stream count and listening
+// time. TrackIDs holds the track itself, or the most streamed tracks of an
+// artist or album.
+type Ranked struct {
+	Name     string
+	Artists  []string
+	Streams  int
+	Ms       int64
+	TrackIDs []string
+}
+
+// Streak is a run of consecutive days with at least one stream.
+type Streak struct {
+	Days  int
+	Start time.Time
+	End   time.Time
+}
+
+// rankedBuilder accumulates one Ranked entry.
+type rankedBuilder struct {
+	Ranked
+	trackStreams map[string]int
+}
+
+// ComputeStats summarizes the listens in events, keeping the top entries of
+// each ranking. Days are in loc.
+func ComputeStats(events []Event, top int, now time.Time, loc *time.Location) Stats {
+	var stats Stats
+
+	tracks := map[string]*rankedBuilder{}
+	artists := map[string]*rankedBuilder{}
+	albums := map[string]*rankedBuilder{}
+	days := map[string]bool{}
+
+	add := func(builders map[string]*rankedBuilder, key, name string, names []string, event Event) {
+		builder, ok := builders[key]
+		if !ok {
+			builder = &rankedBuilder{Ranked: Ranked{Name: name, Artists: names}, trackStreams: map[string]int{}}
+			builders[key] = builder
+		}
+		builder.Streams++
+		builder.Ms += int64(event.PlayedMs)
+		if event.TrackID != "" {
+			builder.trackStreams[event.TrackID]++
+		}
+	}
+
+	for _, event := range events {
+		if !event.IsListen() {
+			continue
+		}
+
+		if stats.From.IsZero() || event.Time.Before(stats.From) {
+			stats.From = event.Time
+		}
+		if event.Time.After(stats.To) {
+			stats.To = event.Time
+		}
+
+		stats.Listens++
+		stats.TotalMs += int64(event.PlayedMs)
+		if event.Type == EventSkip {
+			stats.Skips++
+		}
+
+		local := event.StartedAt
+		if local.IsZero() {
+			local = event.Time
+		}
+		local = local.In(loc)
+		stats.ByHour[local.Hour()] += int64(event.PlayedMs)
+		stats.ByDay[local.Weekday()] += int64(event.PlayedMs)
+
+		shortTrack := event.DurationMs > 0 && event.DurationMs <= minStreamMs && event.Type == EventComplete
+		if event.PlayedMs < minStreamMs && !shortTrack {
+			continue
+		}
+
+		stats.Streams++
+		days[local.Format("2006-01-02")] = true
+
+		trackKey := event.TrackID
+		if trackKey == "" {
+			trackKey = strings.ToLower(event.Track + "\x00" + strings.Join(event.Artists, ","))
+		}
+		add(tracks, trackKey, event.Track, event.Artists, event)
+
+		for _, artist := range event.Artists {
+			add(artists, strings.ToLower(artist), artist, nil, event)
+		}
+
+		if event.Album != "" {
+			albumKey := strings.ToLower(event.Album + "\x00" + firstArtist(event.Artists))
+			add(albums, albumKey, event.Album, firstArtists(event.Artists), event)
+		}
+	}
+
+	stats.Distinct = len(tracks)
+	stats.Tracks = topRanked(tracks, top)
+	stats.Artists = topRanked(artists, top)
+	stats.Albums = topRanked(albums, top)
+	stats.Days = len(days)
+	stats.Longest, stats.Current = streaks(days, now.In(loc))
+
+	return stats
+}
+
+func firstArtist(artists []string) string {
+	if len(artists) == 0 {
+		return ""
+	}
+	return artists[0]
+}
+
+func firstArtists(artists []string) []string {
+	if len(artists) == 0 {
+		return nil
+	}
+	return artists[:1]
+}
+
+func topRanked(builders map[string]*rankedBuilder, top int) []Ranked {
+	ranked := make([]Ranked, 0, len(builders))
+	for _, builder := range builders {
+		entry := builder.Ranked
+
+		ids := make([]string, 0, len(builder.trackStreams))
+		for id := range builder.trackStreams {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if builder.trackStreams[ids[i]] != builder.trackStreams[ids[j]] {
+				return builder.trackStreams[ids[i]] > builder.trackStreams[ids[j]]
+			}
+			return ids[i] < ids[j]
+		})
+		if len(ids) > maxTrackIDsPerEntry {
+			ids = ids[:maxTrackIDsPerEntry]
+		}
+		entry.TrackIDs = ids
+
+		ranked = append(ranked, entry)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Streams != ranked[j].Streams {
+			return ranked[i].Streams > ranked[j].Streams
+		}
+		if ranked[i].Ms != ranked[j].Ms {
+			return ranked[i].Ms > ranked[j].Ms
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+
+	if top > 0 && len(ranked) > top {
+		ranked = ranked[:top]
+	}
+
+	return ranked
+}
+
+// streaks finds the longest run of listening days, and the run that ends
+// today or yesterday.
+func streaks(days map[string]bool, now time.Time) (Streak, Streak) {
+	if len(days) == 0 {
+		return Streak{}, Streak{}
+	}
+
+	var dates []time.Time
+	for day := range days {
+		date, err := time.ParseInLocation("2006-01-02", day, now.Location())
+		if err == nil {
+			dates = append(dates, date)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	var longest, run Streak
+	for i, date := range dates {
+		if i > 0 && dates[i-1].AddDate(0, 0, 1).Equal(date) {
+			run.Days++
+			run.End = date
+		} else {
+			run = Streak{Days: 1, Start: date, End: date}
+		}
+		if run.Days > longest.Days {
+			longest = run
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	current := Streak{}
+	if run.End.Equal(today) || run.End.Equal(today.AddDate(0, 0, -1)) {
+		current = run
+	}
+
+	return longest, current
+}
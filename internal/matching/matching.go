@@ -0,0 +1,149 @@
+// Package matching compares free-text track, artist and playlist names, for
+// finding the Spotify item a user means and spotting duplicates.
+package matching
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// featuringPattern matches "(feat. X)", "[with X]" and trailing "ft. X".
+	featuringPattern = regexp.MustCompile(`(?i)[(\[]\s*(feat\.?|ft\.?|featuring|with)\s[^)\]]*[)\]]|\s(feat\.?|ft\.?|featuring)\s.*$`)
+	// versionPattern matches version suffixes such as " - Remastered 2011" or
+	// "(2011 Remaster)".
+	versionPattern = regexp.MustCompile(`(?i)\s+-\s+.*\b(remaster(ed)?|version|edit|mix|mono|stereo|live|deluxe|anniversary)\b.*$|[(\[][^)\]]*\b(remaster(ed)?|version|edit|mono|stereo|deluxe|anniversary)\b[^)\]]*[)\]]`)
+	// byPattern matches the " by " between a title and an artist.
+	byPattern = regexp.MustCompile(`(?i)\s+by\s+`)
+)
+
+// accents maps common accented letters to their plain form.
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ç", "c", "ý", "y", "ÿ", "y", "ß", "ss",
+)
+
+// Normalize lowercases s, strips accents and punctuation and collapses
+// whitespace, so that "Beyoncé – Halo!" and "beyonce halo" compare equal.
+func Normalize(s string) string {
+	s = accents.Replace(strings.ToLower(s))
+	s = strings.ReplaceAll(s, "&", " and ")
+
+	var builder strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			builder.WriteRune(r)
+		case r == '\'' || r == '’':
+			// Drop apostrophes so "don't" matches "dont".
+		default:
+			builder.WriteRune(' ')
+		}
+	}
+
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// NormalizeTitle normalizes a track or album title, also dropping featured
+// artists and version suffixes such as "Remastered 2011".
+func NormalizeTitle(title string) string {
+	title = featuringPattern.ReplaceAllString(title, "")
+	title = versionPattern.ReplaceAllString(title, "")
+	return Normalize(title)
+}
+
+// Similarity scores how alike a and b are, from 0 (nothing in common) to 1
+// (equal after normalizing). It blends word overlap with character bigram
+// overlap, so it tolerates both reordered words and small typos.
+func Similarity(a, b string) float64 {
+	a, b = Normalize(a), Normalize(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	return 0.5*dice(strings.Fields(a), strings.Fields(b)) + 0.5*dice(bigrams(a), bigrams(b))
+}
+
+// TitleSimilarity is Similarity for titles, ignoring featured artists and
+// version suffixes.
+func TitleSimilarity(a, b string) float64 {
+	return Similarity(NormalizeTitle(a), NormalizeTitle(b))
+}
+
+// BestSimilarity returns the highest Similarity between want and any of
+// candidates.
+func BestSimilarity(want string, candidates []string) float64 {
+	best := 0.0
+	for _, candidate := range candidates {
+		if score := Similarity(want, candidate); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// dice is the Sørensen–Dice coefficient of two multisets.
+func dice(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	counts := map[string]int{}
+	for _, item := range a {
+		counts[item]++
+	}
+
+	shared := 0
+	for _, item := range b {
+		if counts[item] > 0 {
+			counts[item]--
+			shared++
+		}
+	}
+
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
+
+func bigrams(s string) []string {
+	runes := []rune(strings.ReplaceAll(s, " ", ""))
+	if len(runes) < 2 {
+		return []string{string(runes)}
+	}
+
+	result := make([]string, 0, len(runes)-1)
+	for i := 0; i < len(runes)-1; i++ {
+		result = append(result, string(runes[i:i+2]))
+	}
+	return result
+}
+
+// SplitTitleArtist splits queries such as "Queen - Bohemian Rhapsody" or
+// "Bohemian Rhapsody by Queen" into a title and artist. A dash is tried
+// first, since titles such as "Stand by Me" contain " by ". The artist is
+// empty when the query has no recognizable separator.
+func SplitTitleArtist(query string) (string, string) {
+	query = strings.TrimSpace(query)
+
+	for _, separator := range []string{" - ", " – ", " — "} {
+		if artist, title, ok := strings.Cut(query, separator); ok {
+			return strings.TrimSpace(title), strings.TrimSpace(artist)
+		}
+	}
+
+	// The separator is found in query itself, since lowercasing can change
+	// the length of a string.
+	if matches := byPattern.FindAllStringIndex(query, -1); len(matches) > 0 {
+		last := matches[len(matches)-1]
+		return strings.TrimSpace(query[:last[0]]), strings.TrimSpace(query[last[1]:])
+	}
+
+	return query, ""
+}
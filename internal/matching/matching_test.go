@@ -0,0 +1,31 @@
+package matching
+
+import "testing"
+
+func TestSplitTitleArtist(t *testing.T) {
+	tests := []struct {
+		query  string
+		title  string
+		artist string
+	}{
+		{"Bohemian Rhapsody by Queen", "Bohemian Rhapsody", "Queen"},
+		{"Bohemian Rhapsody BY Queen", "Bohemian Rhapsody", "Queen"},
+		{"Stand by Me by Ben E. King", "Stand by Me", "Ben E. King"},
+		{"Queen - Bohemian Rhapsody", "Bohemian Rhapsody", "Queen"},
+		{"Queen – Bohemian Rhapsody", "Bohemian Rhapsody", "Queen"},
+		{"Ben E. King - Stand by Me", "Stand by Me", "Ben E. King"},
+		{"  Bohemian Rhapsody  ", "Bohemian Rhapsody", ""},
+		{"Standby", "Standby", ""},
+		{"ȺȺȺȺ by X", "ȺȺȺȺ", "X"},
+		{"İİİİ by X", "İİİİ", "X"},
+		{"Déjà Vu by Beyoncé", "Déjà Vu", "Beyoncé"},
+		{"ȺȺȺȺ - İİİİ", "İİİİ", "ȺȺȺȺ"},
+	}
+
+	for _, test := range tests {
+		title, artist := SplitTitleArtist(test.query)
+		if title != test.title || artist != test.artist {
+			t.Errorf("SplitTitleArtist(%q) = %q, %q, want %q, %q", test.query, title, artist, test.title, test.artist)
+		}
+	}
+}
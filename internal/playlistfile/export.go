@@ -0,0 +1,230 @@
+package playlistfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// csvHeader is the first row of a CSV export. Artists are joined with "; "
+// since artist names may contain commas.
+var csvHeader = []string{"track_id", "uri", "isrc", "title", "artists", "album", "duration_ms", "added_at", "added_by"}
+
+const artistSeparator = "; "
+
+func encodeCSV(playlist Playlist) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	for _, track := range playlist.Tracks {
+		row := []string{
+			track.ID,
+			track.URI,
+			track.ISRC,
+			track.Title,
+			strings.Join(track.Artists, artistSeparator),
+			track.Album,
+			strconv.Itoa(track.DurationMs),
+			track.AddedAt,
+			track.AddedBy,
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeM3U8 writes an extended M3U playlist. Each entry has an #EXTINF line
+// with the length in seconds and "Artists - Title", #EXTART and #EXTALB
+// lines, and the track's Spotify URL (or URI for local files) as location.
+func encodeM3U8(playlist Playlist) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("#EXTM3U\n")
+	if playlist.Name != "" {
+		fmt.Fprintf(&buf, "#PLAYLIST:%s\n", m3uLine(playlist.Name))
+	}
+
+	for _, track := range playlist.Tracks {
+		artists := strings.Join(track.Artists, ", ")
+		fmt.Fprintf(&buf, "\n#EXTINF:%d,%s\n", (track.DurationMs+500)/1000, m3uLine(joinArtistTitle(artists, track.Title)))
+		if artists != "" {
+			fmt.Fprintf(&buf, "#EXTART:%s\n", m3uLine(artists))
+		}
+		if track.Album != "" {
+			fmt.Fprintf(&buf, "#EXTALB:%s\n", m3uLine(track.Album))
+		}
+
+		location := track.URL()
+		if location == "" {
+			location = track.URI
+		}
+		buf.WriteString(location + "\n")
+	}
+
+	return buf.Bytes()
+}
+
+func joinArtistTitle(artists, title string) string {
+	if artists == "" {
+		return title
+	}
+	return artists + " - " + title
+}
+
+// m3uLine keeps a value on one line.
+func m3uLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// XSPF has no fields for the ISRC or when and by whom a track was added, so
+// they go into meta elements with these rel values.
+const (
+	metaISRC    = "isrc"
+	metaAddedAt = "added_at"
+	metaAddedBy = "added_by"
+)
+
+type xspfPlaylist struct {
+	XMLName    xml.Name    `xml:"http://xspf.org/ns/0/ playlist"`
+	Version    string      `xml:"version,attr"`
+	Title      string      `xml:"title,omitempty"`
+	Creator    string      `xml:"creator,omitempty"`
+	Annotation string      `xml:"annotation,omitempty"`
+	Location   string      `xml:"location,omitempty"`
+	Tracks     []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location   string     `xml:"location,omitempty"`
+	Identifier string     `xml:"identifier,omitempty"`
+	Title      string     `xml:"title,omitempty"`
+	Creator    string     `xml:"creator,omitempty"`
+	Album      string     `xml:"album,omitempty"`
+	Duration   int        `xml:"duration,omitempty"`
+	Meta       []xspfMeta `xml:"meta"`
+}
+
+type xspfMeta struct {
+	Rel   string `xml:"rel,attr"`
+	Value string `xml:",chardata"`
+}
+
+func encodeXSPF(playlist Playlist) ([]byte, error) {
+	document := xspfPlaylist{
+		Version:    "1",
+		Title:      playlist.Name,
+		Creator:    playlist.Owner,
+		Annotation: playlist.Description,
+		Location:   playlist.URL,
+	}
+
+	for _, track := range playlist.Tracks {
+		entry := xspfTrack{
+			Location:   track.URL(),
+			Identifier: track.URI,
+			Title:      track.Title,
+			Creator:    strings.Join(track.Artists, ", "),
+			Album:      track.Album,
+			Duration:   track.DurationMs,
+		}
+		for _, meta := range trackMeta(track) {
+			entry.Meta = append(entry.Meta, xspfMeta{Rel: meta[0], Value: meta[1]})
+		}
+		document.Tracks = append(document.Tracks, entry)
+	}
+
+	data, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// jspfTrack follows the JSPF spec, where location, identifier and meta are
+// lists.
+type jspfTrack struct {
+	Location   []string            `json:"location,omitempty"`
+	Identifier []string            `json:"identifier,omitempty"`
+	Title      string              `json:"title,omitempty"`
+	Creator    string              `json:"creator,omitempty"`
+	Album      string              `json:"album,omitempty"`
+	Duration   int                 `json:"duration,omitempty"`
+	Meta       []map[string]string `json:"meta,omitempty"`
+}
+
+type jspfPlaylist struct {
+	Title      string      `json:"title,omitempty"`
+	Creator    string      `json:"creator,omitempty"`
+	Annotation string      `json:"annotation,omitempty"`
+	Location   string      `json:"location,omitempty"`
+	Track      []jspfTrack `json:"track"`
+}
+
+func encodeJSPF(playlist Playlist) ([]byte, error) {
+	document := jspfPlaylist{
+		Title:      playlist.Name,
+		Creator:    playlist.Owner,
+		Annotation: playlist.Description,
+		Location:   playlist.URL,
+		Track:      []jspfTrack{},
+	}
+
+	for _, track := range playlist.Tracks {
+		entry := jspfTrack{
+			Title:    track.Title,
+			Creator:  strings.Join(track.Artists, ", "),
+			Album:    track.Album,
+			Duration: track.DurationMs,
+		}
+		if url := track.URL(); url != "" {
+			entry.Location = []string{url}
+		}
+		if track.URI != "" {
+			entry.Identifier = []string{track.URI}
+		}
+		for _, meta := range trackMeta(track) {
+			entry.Meta = append(entry.Meta, map[string]string{meta[0]: meta[1]})
+		}
+		document.Track = append(document.Track, entry)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(map[string]jspfPlaylist{"playlist": document}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// trackMeta returns the rel and value of each meta field the track has.
+func trackMeta(track Track) [][2]string {
+	var meta [][2]string
+	if track.ISRC != "" {
+		meta = append(meta, [2]string{metaISRC, track.ISRC})
+	}
+	if track.AddedAt != "" {
+		meta = append(meta, [2]string{metaAddedAt, track.AddedAt})
+	}
+	if track.AddedBy != "" {
+		meta = append(meta, [2]string{metaAddedBy, track.AddedBy})
+	}
+	return meta
+}
@@ -0,0 +1,347 @@
+package playlistfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"spotify-mcp/internal/matching"
+)
+
+// Formats read by Parse besides CSV.
+const (
+	FormatM3U  = "m3u"
+	FormatText = "text"
+)
+
+// ImportFormats lists the formats Parse reads. M3U covers M3U8 too.
+var ImportFormats = []string{FormatCSV, FormatM3U, FormatText}
+
+var (
+	// numberingPattern matches list numbering such as "1. ", "01 - " or "3) ".
+	numberingPattern = regexp.MustCompile(`^\d{1,3}\s*([.):-])\s+`)
+	// timestampPattern matches DJ set timestamps such as "[00:03:15] ".
+	timestampPattern = regexp.MustCompile(`^\[?\d{1,2}:\d{2}(:\d{2})?\]?\s+`)
+	// spotifyLinkPattern finds a Spotify track URI or URL in a line.
+	spotifyLinkPattern = regexp.MustCompile(`spotify:track:[0-9A-Za-z]+|https?://open\.spotify\.com/(intl-[a-z]+/)?track/[0-9A-Za-z]+`)
+)
+
+// Entry is one track read from a file. Any of its fields may be empty; URI
+// holds a Spotify track URI or URL found in the file.
+type Entry struct {
+	// Line is the line, or CSV row, the entry came from, counting from 1.
+	Line int
+	// Source is the line as written, for reporting.
+	Source     string
+	Title      string
+	Artist     string
+	Album      string
+	ISRC       string
+	URI        string
+	DurationMs int
+}
+
+// DetectFormat guesses the format of a file from its name, then its content.
+func DetectFormat(name string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".m3u", ".m3u8":
+		return FormatM3U
+	case ".txt":
+		return FormatText
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("#EXTM3U")) || bytes.HasPrefix(trimmed, []byte("#EXTINF")) {
+		return FormatM3U
+	}
+
+	firstLine, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	if _, ok := csvColumns(splitCSVLine(string(firstLine))); ok {
+		return FormatCSV
+	}
+
+	return FormatText
+}
+
+// Parse reads the entries of a file in format.
+func Parse(format string, data []byte) ([]Entry, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	switch format {
+	case FormatCSV:
+		return parseCSV(data)
+	case FormatM3U, FormatM3U8:
+		return parseM3U(data), nil
+	case FormatText:
+		return parseText(data), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, use one of %s", format, strings.Join(ImportFormats, ", "))
+	}
+}
+
+func parseText(data []byte) []Entry {
+	var entries []Entry
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		entry := parseTrackLine(line)
+		entry.Line = i + 1
+		entry.Source = line
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// parseTrackLine reads "Artist - Title", "Title by Artist" or a Spotify
+// link, ignoring list numbering and timestamps.
+func parseTrackLine(line string) Entry {
+	if link := spotifyLinkPattern.FindString(line); link != "" {
+		return Entry{URI: link}
+	}
+
+	line = timestampPattern.ReplaceAllString(line, "")
+	line = stripNumbering(line)
+	line = strings.TrimSpace(line)
+
+	if artist, title, ok := cutArtistTitle(line); ok {
+		return Entry{Title: title, Artist: artist}
+	}
+
+	title, artist := matching.SplitTitleArtist(line)
+	return Entry{Title: title, Artist: artist}
+}
+
+// cutArtistTitle splits "Artist - Title" at the first dash.
+func cutArtistTitle(line string) (string, string, bool) {
+	for _, separator := range []string{" - ", " – ", " — "} {
+		if artist, title, ok := strings.Cut(line, separator); ok {
+			return strings.TrimSpace(artist), strings.TrimSpace(title), true
+		}
+	}
+	return "", "", false
+}
+
+// stripNumbering removes list numbering from the start of line. Numbering
+// followed by a dash or colon could as well be an artist such as "311 -
+// Amber", so it is only removed when an "Artist - Title" line remains.
+func stripNumbering(line string) string {
+	match := numberingPattern.FindStringSubmatchIndex(line)
+	if match == nil {
+		return line
+	}
+
+	rest := line[match[1]:]
+	if separator := line[match[2]:match[3]]; separator == "-" || separator == ":" {
+		if _, _, ok := cutArtistTitle(strings.TrimSpace(rest)); !ok {
+			return line
+		}
+	}
+	return rest
+}
+
+// parseM3U reads #EXTINF titles and lengths, #EXTART and #EXTALB lines, and
+// Spotify links given as locations. Entries without #EXTINF are named after
+// their file.
+func parseM3U(data []byte) []Entry {
+	var entries []Entry
+	var pending *Entry
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#EXTINF:"):
+			info := strings.TrimPrefix(line, "#EXTINF:")
+			length, title, _ := strings.Cut(info, ",")
+			entry := parseTrackLine(title)
+			entry.Line = i + 1
+			entry.Source = strings.TrimSpace(title)
+			// The length may be followed by attributes such as tvg-id="".
+			lengthField, _, _ := strings.Cut(strings.TrimSpace(length), " ")
+			if seconds, err := strconv.Atoi(lengthField); err == nil && seconds > 0 {
+				entry.DurationMs = seconds * 1000
+			}
+			pending = &entry
+		case strings.HasPrefix(line, "#EXTART:"):
+			if pending != nil {
+				pending.Artist = strings.TrimSpace(strings.TrimPrefix(line, "#EXTART:"))
+			}
+		case strings.HasPrefix(line, "#EXTALB:"):
+			if pending != nil {
+				pending.Album = strings.TrimSpace(strings.TrimPrefix(line, "#EXTALB:"))
+			}
+		case strings.HasPrefix(line, "#"):
+			continue
+		default:
+			var entry Entry
+			if pending != nil {
+				entry = *pending
+				pending = nil
+			} else {
+				base := filepath.Base(strings.ReplaceAll(line, "\\", "/"))
+				entry = parseTrackLine(strings.TrimSuffix(base, filepath.Ext(base)))
+				entry.Line = i + 1
+				entry.Source = line
+			}
+			if link := spotifyLinkPattern.FindString(line); link != "" {
+				entry.URI = link
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// csvColumn is a field Parse reads from CSV files.
+type csvColumn int
+
+const (
+	columnTitle csvColumn = iota
+	columnArtist
+	columnAlbum
+	columnISRC
+	columnURI
+	columnID
+	columnDuration
+)
+
+// csvHeaderNames maps normalized header names, as written by this package,
+// Exportify and similar tools, to columns.
+var csvHeaderNames = map[string]csvColumn{
+	"title":           columnTitle,
+	"track":           columnTitle,
+	"trackname":       columnTitle,
+	"name":            columnTitle,
+	"song":            columnTitle,
+	"artist":          columnArtist,
+	"artists":         columnArtist,
+	"artistname":      columnArtist,
+	"artistnames":     columnArtist,
+	"album":           columnAlbum,
+	"albumname":       columnAlbum,
+	"isrc":            columnISRC,
+	"uri":             columnURI,
+	"trackuri":        columnURI,
+	"spotifyuri":      columnURI,
+	"spotifytrackuri": columnURI,
+	"trackid":         columnID,
+	"id":              columnID,
+	"spotifyid":       columnID,
+	"durationms":      columnDuration,
+	"trackdurationms": columnDuration,
+}
+
+// csvColumns maps a header row to column indexes. It reports false if the row
+// names no column a track can be found by.
+func csvColumns(header []string) (map[csvColumn]int, bool) {
+	columns := map[csvColumn]int{}
+	for i, name := range header {
+		key := strings.ReplaceAll(matching.Normalize(name), " ", "")
+		if column, ok := csvHeaderNames[key]; ok {
+			if _, seen := columns[column]; !seen {
+				columns[column] = i
+			}
+		}
+	}
+
+	_, title := columns[columnTitle]
+	_, uri := columns[columnURI]
+	_, id := columns[columnID]
+	_, isrc := columns[columnISRC]
+	return columns, title || uri || id || isrc
+}
+
+func splitCSVLine(line string) []string {
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.LazyQuotes = true
+	record, err := reader.Read()
+	if err != nil {
+		return nil
+	}
+	return record
+}
+
+// parseCSV reads a CSV with a header row naming its columns. Without a
+// recognizable header, rows are read as artist and title, or as a single
+// "Artist - Title" column.
+func parseCSV(data []byte) ([]Entry, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns, hasHeader := csvColumns(records[0])
+	field := func(record []string, column csvColumn) string {
+		index, ok := columns[column]
+		if !ok || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	var entries []Entry
+	for i, record := range records {
+		if hasHeader && i == 0 {
+			continue
+		}
+
+		source := strings.Join(record, ", ")
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		var entry Entry
+		switch {
+		case hasHeader:
+			entry = Entry{
+				Title:  field(record, columnTitle),
+				Artist: firstArtist(field(record, columnArtist)),
+				Album:  field(record, columnAlbum),
+				ISRC:   field(record, columnISRC),
+				URI:    field(record, columnURI),
+			}
+			if id := field(record, columnID); entry.URI == "" && id != "" {
+				entry.URI = "spotify:track:" + id
+			}
+			if ms, err := strconv.Atoi(field(record, columnDuration)); err == nil {
+				entry.DurationMs = ms
+			}
+		case len(record) >= 2:
+			entry = Entry{Artist: strings.TrimSpace(record[0]), Title: strings.TrimSpace(record[1])}
+		default:
+			entry = parseTrackLine(record[0])
+		}
+
+		entry.Line = i + 1
+		entry.Source = source
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// firstArtist keeps the first of several artists joined with semicolons, as
+// exports write them, since searching works best with one.
+func firstArtist(artists string) string {
+	first, _, _ := strings.Cut(artists, ";")
+	return strings.TrimSpace(first)
+}
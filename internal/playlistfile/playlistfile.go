@@ -0,0 +1,88 @@
+// Package playlistfile writes playlists to files shared outside Spotify, as
+// CSV, extended M3U, XSPF or JSPF, and reads track lists back from CSV, M3U
+// and plain text.
+package playlistfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formats.
+const (
+	FormatCSV  = "csv"
+	FormatM3U8 = "m3u8"
+	FormatXSPF = "xspf"
+	FormatJSPF = "jspf"
+)
+
+// ExportFormats lists the formats Encode writes.
+var ExportFormats = []string{FormatCSV, FormatM3U8, FormatXSPF, FormatJSPF}
+
+// Playlist is a playlist as written to a file.
+type Playlist struct {
+	Name        string
+	Description string
+	Owner       string
+	URL         string
+	Tracks      []Track
+}
+
+// Track is one playlist entry. Local files have a URI but no ID.
+type Track struct {
+	ID         string
+	URI        string
+	ISRC       string
+	Title      string
+	Artists    []string
+	Album      string
+	DurationMs int
+	AddedAt    string
+	AddedBy    string
+}
+
+// URL returns the open.spotify.com link for the track, or "" for local files.
+func (t Track) URL() string {
+	parts := strings.Split(t.URI, ":")
+	if len(parts) != 3 || parts[0] != "spotify" || parts[1] == "local" {
+		return ""
+	}
+	return fmt.Sprintf("https://open.spotify.com/%s/%s", parts[1], parts[2])
+}
+
+// Encode writes the playlist in format.
+func Encode(format string, playlist Playlist) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		return encodeCSV(playlist)
+	case FormatM3U8:
+		return encodeM3U8(playlist), nil
+	case FormatXSPF:
+		return encodeXSPF(playlist)
+	case FormatJSPF:
+		return encodeJSPF(playlist)
+	default:
+		return nil, fmt.Errorf("unknown format %q, use one of %s", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+// FileName turns a playlist name into a safe file name with the format's
+// extension.
+func FileName(name, format string) string {
+	var builder strings.Builder
+	for _, r := range strings.TrimSpace(name) {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ':
+			builder.WriteRune('_')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	base := strings.Trim(builder.String(), ". ")
+	if base == "" {
+		base = "playlist"
+	}
+
+	return base + "." + format
+}
@@ -0,0 +1,296 @@
+// Package resolve finds the Spotify track meant by a title and artist, an
+// ISRC or a link, scoring how sure the match is.
+package resolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/matching"
+)
+
+const (
+	// MinScore is the lowest score a search match is accepted at.
+	MinScore = 0.6
+	// searchLimit is how many search results are scored per query.
+	searchLimit = 10
+	// durationToleranceMs is how far a match's length may be from a known
+	// length before its score is lowered.
+	durationToleranceMs = 10_000
+	// DefaultWorkers is how many queries ResolveAll looks up at once by
+	// default, few enough to stay clear of Spotify's rate limits.
+	DefaultWorkers = 4
+)
+
+// Methods a track was resolved by.
+const (
+	MethodLink   = "link"
+	MethodISRC   = "isrc"
+	MethodSearch = "search"
+)
+
+// Query describes a wanted track. ID is set for Spotify links, which are
+// looked up directly.
+type Query struct {
+	ID         spotify.ID
+	ISRC       string
+	Title      string
+	Artist     string
+	Album      string
+	DurationMs int
+}
+
+// Match is a candidate track with its score from 0 to 1.
+type Match struct {
+	ID         spotify.ID
+	URI        spotify.URI
+	Title      string
+	Artists    []string
+	Album      string
+	DurationMs int
+	Score      float64
+}
+
+// Result holds the candidates for a query, best first. The query is resolved
+// when Method is set; otherwise the candidates scored below MinScore.
+type Result struct {
+	Query   Query
+	Method  string
+	Matches []Match
+	// Err is set by ResolveAll when the lookup failed.
+	Err error
+}
+
+// Resolved reports whether a match was accepted.
+func (r Result) Resolved() bool {
+	return r.Method != "" && len(r.Matches) > 0
+}
+
+// Best returns the accepted match, or the closest candidate if none was.
+func (r Result) Best() (Match, bool) {
+	if len(r.Matches) == 0 {
+		return Match{}, false
+	}
+	return r.Matches[0], true
+}
+
+// Resolve looks a query up by link, then by ISRC, then by searching for its
+// title and artist.
+func Resolve(ctx context.Context, spotifyClient *spotify.Client, query Query) (Result, error) {
+	result := Result{Query: query}
+
+	if query.ID != "" {
+		track, err := spotifyClient.GetTrack(ctx, query.ID)
+		var spotifyError spotify.Error
+		switch {
+		case err == nil:
+			match := newMatch(*track)
+			match.Score = 1
+			result.Method = MethodLink
+			result.Matches = []Match{match}
+			return result, nil
+		case errors.As(err, &spotifyError) && (spotifyError.Status == 400 || spotifyError.Status == 404):
+			// A dead link; fall back to the other fields.
+		default:
+			return result, fmt.Errorf("failed to look up track %s: %w", query.ID, err)
+		}
+	}
+
+	if isrc := strings.ToUpper(strings.TrimSpace(query.ISRC)); isrc != "" {
+		results, err := spotifyClient.Search(ctx, "isrc:"+isrc, spotify.SearchTypeTrack, spotify.Limit(searchLimit))
+		if err != nil {
+			return result, fmt.Errorf("failed to search ISRC %s: %w", isrc, err)
+		}
+
+		var matches []Match
+		if results.Tracks != nil {
+			for _, track := range results.Tracks.Tracks {
+				if strings.ToUpper(track.ExternalIDs["isrc"]) != isrc {
+					continue
+				}
+				match := newMatch(track)
+				// Prefer the release whose title matches best; an ISRC
+				// match is certain either way.
+				match.Score = 1
+				if query.Title != "" {
+					match.Score = 0.9 + 0.1*matching.TitleSimilarity(query.Title, track.Name)
+				}
+				matches = append(matches, match)
+			}
+		}
+
+		if len(matches) > 0 {
+			sortMatches(matches)
+			matches[0].Score = 1
+			result.Method = MethodISRC
+			result.Matches = matches
+			return result, nil
+		}
+	}
+
+	if strings.TrimSpace(query.Title) == "" {
+		return result, nil
+	}
+
+	tracks, err := searchTracks(ctx, spotifyClient, query)
+	if err != nil {
+		return result, err
+	}
+
+	for _, track := range tracks {
+		match := newMatch(track)
+		match.Score = scoreTrack(query, track)
+		result.Matches = append(result.Matches, match)
+	}
+	sortMatches(result.Matches)
+
+	if len(result.Matches) > 0 && result.Matches[0].Score >= MinScore {
+		result.Method = MethodSearch
+	}
+
+	return result, nil
+}
+
+// ResolveAll resolves queries with a fixed number of workers and returns the
+// results in the same order. A failed lookup sets the result's Err rather
+// than stopping the others; once ctx is done the remaining queries fail with
+// its error.
+func ResolveAll(ctx context.Context, spotifyClient *spotify.Client, queries []Query, workers int) []Result {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	workers = min(workers, len(queries))
+
+	results := make([]Result, len(queries))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i] = Result{Query: queries[i], Err: err}
+					continue
+				}
+
+				result, err := Resolve(ctx, spotifyClient, queries[i])
+				result.Err = err
+				results[i] = result
+			}
+		}()
+	}
+
+	for i := range queries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// searchTracks searches with field filters first and falls back to a plain
+// query, which copes better with typos.
+func searchTracks(ctx context.Context, spotifyClient *spotify.Client, query Query) ([]spotify.FullTrack, error) {
+	title := strings.ReplaceAll(query.Title, `"`, "")
+	artist := strings.ReplaceAll(query.Artist, `"`, "")
+
+	searches := []string{fmt.Sprintf("track:\"%s\"", title)}
+	if artist != "" {
+		searches[0] += fmt.Sprintf(" artist:\"%s\"", artist)
+	}
+	searches = append(searches, strings.TrimSpace(title+" "+artist))
+
+	var tracks []spotify.FullTrack
+	seen := map[spotify.ID]bool{}
+	for _, search := range searches {
+		results, err := spotifyClient.Search(ctx, search, spotify.SearchTypeTrack, spotify.Limit(searchLimit))
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for %q: %w", search, err)
+		}
+		if results.Tracks == nil {
+			continue
+		}
+
+		for _, track := range results.Tracks.Tracks {
+			if !seen[track.ID] {
+				seen[track.ID] = true
+				tracks = append(tracks, track)
+			}
+		}
+
+		if len(tracks) > 0 && bestScore(query, tracks) >= MinScore {
+			break
+		}
+	}
+
+	return tracks, nil
+}
+
+func bestScore(query Query, tracks []spotify.FullTrack) float64 {
+	best := 0.0
+	for _, track := range tracks {
+		best = max(best, scoreTrack(query, track))
+	}
+	return best
+}
+
+// scoreTrack weighs title and artist similarity, lowered when a known length
+// or album doesn't match, with popularity breaking ties.
+func scoreTrack(query Query, track spotify.FullTrack) float64 {
+	artists := artistNames(track.Artists)
+
+	score := matching.TitleSimilarity(query.Title, track.Name)
+	if query.Artist != "" {
+		artistScore := max(matching.BestSimilarity(query.Artist, artists), matching.Similarity(query.Artist, strings.Join(artists, " ")))
+		score = 0.6*score + 0.4*artistScore
+	}
+
+	if query.DurationMs > 0 && abs(query.DurationMs-int(track.Duration)) > durationToleranceMs {
+		score *= 0.85
+	}
+	if query.Album != "" && matching.TitleSimilarity(query.Album, track.Album.Name) < 0.5 {
+		score *= 0.95
+	}
+
+	return 0.97*score + 0.03*float64(track.Popularity)/100
+}
+
+func sortMatches(matches []Match) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+}
+
+func newMatch(track spotify.FullTrack) Match {
+	return Match{
+		ID:         track.ID,
+		URI:        track.URI,
+		Title:      track.Name,
+		Artists:    artistNames(track.Artists),
+		Album:      track.Album.Name,
+		DurationMs: int(track.Duration),
+	}
+}
+
+func artistNames(artists []spotify.SimpleArtist) []string {
+	names := make([]string, len(artists))
+	for i, artist := range artists {
+		names[i] = artist.Name
+	}
+	return names
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
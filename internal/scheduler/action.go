@@ -0,0 +1,166 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	ActionPause       = "pause"
+	ActionFadeOut     = "fade_out"
+	ActionPlayContext = "play_context"
+	ActionResume      = "resume"
+	ActionSetVolume   = "set_volume"
+
+	defaultFadeOutSeconds = 30
+)
+
+// ActionTypes lists the supported action types, for tool descriptions.
+var ActionTypes = []string{ActionPause, ActionFadeOut, ActionPlayContext, ActionResume, ActionSetVolume}
+
+// Action is a playback action that can be run later. Each action is carried out
+// by calling one of the existing playback tools.
+type Action struct {
+	Type string `json:"type"`
+	// ContextURI is the album, playlist or artist to play for play_context.
+	ContextURI string `json:"context_uri,omitempty"`
+	// Volume is the level for set_volume. For play_context it is the level
+	// to play at, faded in over FadeSeconds when that is set.
+	Volume *int `json:"volume,omitempty"`
+	// FadeSeconds fades the volume for pause, fade_out and set_volume.
+	FadeSeconds int `json:"fade_seconds,omitempty"`
+	// Device optionally targets a device by ID or name.
+	Device string `json:"device,omitempty"`
+}
+
+// Step is a single tool call.
+type Step struct {
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// Validate checks that the action has the fields its type requires.
+func (a Action) Validate() error {
+	switch a.Type {
+	case ActionPause, ActionFadeOut, ActionResume:
+	case ActionPlayContext:
+		if strings.TrimSpace(a.ContextURI) == "" {
+			return errors.New("play_context requires context_uri")
+		}
+	case ActionSetVolume:
+		if a.Volume == nil {
+			return errors.New("set_volume requires volume")
+		}
+	default:
+		return fmt.Errorf("unknown action %q, use one of: %s", a.Type, strings.Join(ActionTypes, ", "))
+	}
+
+	if a.Volume != nil && (*a.Volume < 0 || *a.Volume > 100) {
+		return errors.New("volume must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// Steps returns the tool calls that carry out the action.
+func (a Action) Steps() []Step {
+	arguments := map[string]interface{}{}
+	if a.Device != "" {
+		arguments["device"] = a.Device
+	}
+
+	switch a.Type {
+	case ActionPause:
+		if a.FadeSeconds > 0 {
+			arguments["fade_seconds"] = a.FadeSeconds
+		}
+		return []Step{{Tool: "pause", Arguments: arguments}}
+	case ActionFadeOut:
+		fadeSeconds := a.FadeSeconds
+		if fadeSeconds <= 0 {
+			fadeSeconds = defaultFadeOutSeconds
+		}
+		arguments["fade_seconds"] = fadeSeconds
+		arguments["restore_volume"] = true
+		return []Step{{Tool: "pause", Arguments: arguments}}
+	case ActionPlayContext:
+		playArguments := withArgument(arguments, "context_uri", a.ContextURI)
+		if a.Volume == nil {
+			return []Step{{Tool: "play", Arguments: playArguments}}
+		}
+		if a.FadeSeconds > 0 {
+			// Start silently and ramp up, e.g. for a wake-up alarm.
+			return []Step{
+				{Tool: "set_volume", Arguments: withArgument(arguments, "level", 0)},
+				{Tool: "play", Arguments: playArguments},
+				{Tool: "fade_volume", Arguments: withArgument(withArgument(arguments, "target", *a.Volume), "seconds", a.FadeSeconds)},
+			}
+		}
+		return []Step{
+			{Tool: "set_volume", Arguments: withArgument(arguments, "level", *a.Volume)},
+			{Tool: "play", Arguments: playArguments},
+		}
+	case ActionResume:
+		return []Step{{Tool: "play", Arguments: arguments}}
+	case ActionSetVolume:
+		if a.FadeSeconds > 0 {
+			arguments["target"] = *a.Volume
+			arguments["seconds"] = a.FadeSeconds
+			return []Step{{Tool: "fade_volume", Arguments: arguments}}
+		}
+		arguments["level"] = *a.Volume
+		return []Step{{Tool: "set_volume", Arguments: arguments}}
+	default:
+		return nil
+	}
+}
+
+// withArgument returns a copy of arguments with key set to value.
+func withArgument(arguments map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(arguments)+1)
+	for k, v := range arguments {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
+
+// String describes the action for tool responses.
+func (a Action) String() string {
+	description := a.Type
+	switch a.Type {
+	case ActionPlayContext:
+		description += " " + a.ContextURI
+	}
+
+	if a.Volume != nil {
+		description += fmt.Sprintf(" at %d%%", *a.Volume)
+	}
+
+	if a.FadeSeconds > 0 {
+		description += fmt.Sprintf(" (fade %ds)", a.FadeSeconds)
+	}
+
+	if a.Device != "" {
+		description += fmt.Sprintf(" on %s", a.Device)
+	}
+
+	return description
+}
+
+// RunSteps calls each step's tool in order, stopping at the first failure. It
+// returns the combined output of the tools.
+func RunSteps(ctx context.Context, steps []Step) (string, error) {
+	var outputs []string
+	for _, step := range steps {
+		output, err := CallTool(ctx, step.Tool, step.Arguments)
+		if err != nil {
+			return strings.Join(outputs, "\n"), fmt.Errorf("%s failed: %w", step.Tool, err)
+		}
+		outputs = append(outputs, output)
+	}
+
+	return strings.Join(outputs, "\n"), nil
+}
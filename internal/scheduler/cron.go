@@ -0,0 +1,198 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros are shorthands for common schedules.
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@weekdays": "0 0 * * 1-5",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// cronField is the set of values a field matches.
+type cronField struct {
+	values map[int]bool
+	// any is true for fields starting with "*". It matters for the day of
+	// month and day of week fields: when both are restricted either may match.
+	any bool
+}
+
+// CronSchedule is a parsed five field cron expression: minute, hour, day of
+// month, month and day of week, in local time.
+type CronSchedule struct {
+	minute     cronField
+	hour       cronField
+	dayOfMonth cronField
+	month      cronField
+	dayOfWeek  cronField
+}
+
+// ParseCron parses a standard five field cron expression, such as
+// "0 7 * * MON-FRI", or one of the macros @hourly, @daily, @weekly,
+// @weekdays, @monthly and @yearly.
+func ParseCron(expression string) (CronSchedule, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := cronMacros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return CronSchedule{}, fmt.Errorf("invalid cron expression %q: expected 5 fields (minute hour day-of-month month day-of-week)", expression)
+	}
+
+	var schedule CronSchedule
+	var err error
+
+	if schedule.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return CronSchedule{}, fmt.Errorf("invalid minute field: %w", err)
+	}
+	if schedule.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return CronSchedule{}, fmt.Errorf("invalid hour field: %w", err)
+	}
+	if schedule.dayOfMonth, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return CronSchedule{}, fmt.Errorf("invalid day of month field: %w", err)
+	}
+	if schedule.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return CronSchedule{}, fmt.Errorf("invalid month field: %w", err)
+	}
+	if schedule.dayOfWeek, err = parseCronField(fields[4], 0, 7, weekdayNames); err != nil {
+		return CronSchedule{}, fmt.Errorf("invalid day of week field: %w", err)
+	}
+
+	// Both 0 and 7 mean Sunday.
+	if schedule.dayOfWeek.values[7] {
+		schedule.dayOfWeek.values[0] = true
+	}
+
+	return schedule, nil
+}
+
+func parseCronField(field string, minimum, maximum int, names map[string]int) (cronField, error) {
+	result := cronField{values: map[int]bool{}, any: strings.HasPrefix(field, "*")}
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if rangePart, stepPart, ok := strings.Cut(part, "/"); ok {
+			parsedStep, err := strconv.Atoi(stepPart)
+			if err != nil || parsedStep <= 0 {
+				return cronField{}, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = parsedStep
+			part = rangePart
+		}
+
+		start, end := minimum, maximum
+		if part != "*" {
+			startPart, endPart, isRange := strings.Cut(part, "-")
+
+			var err error
+			if start, err = parseCronValue(startPart, minimum, maximum, names); err != nil {
+				return cronField{}, err
+			}
+
+			end = start
+			if isRange {
+				if end, err = parseCronValue(endPart, minimum, maximum, names); err != nil {
+					return cronField{}, err
+				}
+			} else if step > 1 {
+				end = maximum
+			}
+
+			if end < start {
+				return cronField{}, fmt.Errorf("invalid range %q", part)
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			result.values[value] = true
+		}
+	}
+
+	return result, nil
+}
+
+func parseCronValue(value string, minimum, maximum int, names map[string]int) (int, error) {
+	if named, ok := names[strings.ToLower(value)]; ok {
+		return named, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+
+	if number < minimum || number > maximum {
+		return 0, fmt.Errorf("value %d is outside %d-%d", number, minimum, maximum)
+	}
+
+	return number, nil
+}
+
+// Next returns the first time after after that matches the schedule, or the
+// zero time if there is none within the next five years.
+func (c CronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !c.month.values[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.hour.values[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.minute.values[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c CronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := c.dayOfMonth.values[t.Day()]
+	dayOfWeek := c.dayOfWeek.values[int(t.Weekday())]
+
+	switch {
+	case c.dayOfMonth.any && c.dayOfWeek.any:
+		return true
+	case c.dayOfMonth.any:
+		return dayOfWeek
+	case c.dayOfWeek.any:
+		return dayOfMonth
+	default:
+		return dayOfMonth || dayOfWeek
+	}
+}
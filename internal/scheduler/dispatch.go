@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpServer "github.com/mark3labs/mcp-go/server"
+	"spotify-mcp/internal/server/tools"
+)
+
+var (
+	handlers      = map[string]mcpServer.ToolHandlerFunc{}
+	handlersMutex sync.RWMutex
+)
+
+// RegisterTools makes tools available to scheduled actions, so that they run
+// through exactly the same handlers as calls from the MCP client.
+func RegisterTools(entries []tools.ToolEntry) {
+	handlersMutex.Lock()
+	defer handlersMutex.Unlock()
+
+	for _, entry := range entries {
+		handlers[entry.ToolDefinition.Name] = entry.ToolBehaviour
+	}
+}
+
+// HasTool reports whether a tool with name has been registered.
+func HasTool(name string) bool {
+	handlersMutex.RLock()
+	defer handlersMutex.RUnlock()
+
+	_, ok := handlers[name]
+	return ok
+}
+
+// CallTool invokes a registered tool with arguments and returns the text of its
+// result. Tools the scheduler calls report failures as errors or with
+// mcp.NewToolResultError, since a plain text result can't be told apart from
+// success; both, and a missing result, are returned as errors.
+func CallTool(ctx context.Context, name string, arguments map[string]interface{}) (string, error) {
+	handlersMutex.RLock()
+	handler, ok := handlers[name]
+	handlersMutex.RUnlock()
+
+	if !ok {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+
+	result, err := handler(ctx, request)
+	if err != nil {
+		return "", err
+	}
+
+	if result == nil {
+		return "", fmt.Errorf("%s returned no result", name)
+	}
+
+	text := resultText(result)
+	if result.IsError {
+		return "", errors.New(text)
+	}
+
+	return text, nil
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	if result == nil {
+		return ""
+	}
+
+	var texts []string
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, textContent.Text)
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,349 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"spotify-mcp/internal/storage"
+)
+
+const (
+	jobsFile = "jobs.json"
+
+	// maxJobHistory is how many runs are kept per job.
+	maxJobHistory = 10
+	// jobTimeout bounds how long the tool calls of one job run may take.
+	jobTimeout = 2 * time.Minute
+)
+
+// StepTools lists the tools job steps may call: playback control, reads and
+// playlist maintenance, whose changes are journaled and can be undone. Jobs
+// run unattended, so tools that manage jobs, undo changes or remove or
+// rewrite arbitrary playlist tracks are left out.
+var StepTools = []string{
+	"play", "play_query", "pause", "next_track", "previous_track", "seek",
+	"set_volume", "fade_volume", "shuffle", "set_repeat", "transfer_playback",
+	"add_tracks_to_queue", "queue_insert_next", "queue_move", "queue_remove", "queue_clear",
+	"current_track", "playback_state", "get_queue", "list_devices", "recently_played",
+	"add_tracks_to_playlist", "snapshot_playlist", "dedupe_playlist", "sort_playlist",
+	"reshuffle_playlist", "combine_playlists",
+}
+
+var (
+	jobs        []*Job
+	jobsMutex   sync.Mutex
+	jobsChanged = make(chan struct{}, 1)
+	jobsLoaded  bool
+)
+
+// Job is a recurring automation that runs its steps on a cron schedule. Jobs
+// are saved to the data directory so they survive restarts.
+type Job struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Cron      string    `json:"cron"`
+	Steps     []Step    `json:"steps"`
+	Paused    bool      `json:"paused"`
+	CreatedAt time.Time `json:"created_at"`
+	NextRun   time.Time `json:"next_run,omitempty"`
+	History   []JobRun  `json:"history,omitempty"`
+}
+
+// JobRun records the outcome of one run of a job.
+type JobRun struct {
+	StartedAt time.Time `json:"started_at"`
+	Status    string    `json:"status"`
+	Output    string    `json:"output,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// LastRun returns the most recent run of the job, if it has run.
+func (j Job) LastRun() (JobRun, bool) {
+	if len(j.History) == 0 {
+		return JobRun{}, false
+	}
+	return j.History[len(j.History)-1], true
+}
+
+// loadJobs reads the saved jobs. It is called once, when the scheduler starts.
+func loadJobs() error {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	if jobsLoaded {
+		return nil
+	}
+
+	var saved []*Job
+	if _, err := storage.ReadJSON(jobsFile, &saved); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, job := range saved {
+		schedule, err := ParseCron(job.Cron)
+		if err != nil {
+			log.Printf("Skipping job %s: %v", job.ID, err)
+			continue
+		}
+		// Runs missed while the server was stopped are skipped.
+		job.NextRun = schedule.Next(now)
+		jobs = append(jobs, job)
+	}
+
+	jobsLoaded = true
+
+	return nil
+}
+
+// saveJobsLocked writes the jobs to disk. jobsMutex must be held.
+func saveJobsLocked() error {
+	return storage.WriteJSON(jobsFile, jobs)
+}
+
+func notifyJobsChanged() {
+	select {
+	case jobsChanged <- struct{}{}:
+	default:
+	}
+}
+
+// CreateJob validates and saves a new job.
+func CreateJob(name string, cronExpression string, steps []Step) (Job, error) {
+	schedule, err := ParseCron(cronExpression)
+	if err != nil {
+		return Job{}, err
+	}
+
+	if len(steps) == 0 {
+		return Job{}, errors.New("a job needs at least one step")
+	}
+
+	if err := validateSteps(steps); err != nil {
+		return Job{}, err
+	}
+
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	now := time.Now()
+	job := &Job{
+		ID:        fmt.Sprintf("job-%d", now.UnixNano()),
+		Name:      strings.TrimSpace(name),
+		Cron:      strings.TrimSpace(cronExpression),
+		Steps:     steps,
+		CreatedAt: now,
+		NextRun:   schedule.Next(now),
+	}
+
+	if job.NextRun.IsZero() {
+		return Job{}, fmt.Errorf("cron expression %q never matches", cronExpression)
+	}
+
+	jobs = append(jobs, job)
+	if err := saveJobsLocked(); err != nil {
+		jobs = jobs[:len(jobs)-1]
+		return Job{}, err
+	}
+
+	notifyJobsChanged()
+
+	return *job, nil
+}
+
+// ListJobs returns every job, ordered by next run.
+func ListJobs() []Job {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	list := make([]Job, len(jobs))
+	for i, job := range jobs {
+		list[i] = *job
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].NextRun.Before(list[j].NextRun)
+	})
+
+	return list
+}
+
+// SetJobPaused pauses or resumes a job.
+func SetJobPaused(id string, paused bool) (Job, error) {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	job := findJobLocked(id)
+	if job == nil {
+		return Job{}, fmt.Errorf("no job with ID %q", id)
+	}
+
+	job.Paused = paused
+	if !paused {
+		schedule, err := ParseCron(job.Cron)
+		if err != nil {
+			return Job{}, err
+		}
+		job.NextRun = schedule.Next(time.Now())
+	}
+
+	if err := saveJobsLocked(); err != nil {
+		return Job{}, err
+	}
+
+	notifyJobsChanged()
+
+	return *job, nil
+}
+
+// DeleteJob removes a job.
+func DeleteJob(id string) (Job, error) {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	for i, job := range jobs {
+		if job.ID == id {
+			jobs = append(jobs[:i], jobs[i+1:]...)
+			if err := saveJobsLocked(); err != nil {
+				return Job{}, err
+			}
+			notifyJobsChanged()
+			return *job, nil
+		}
+	}
+
+	return Job{}, fmt.Errorf("no job with ID %q", id)
+}
+
+// validateSteps checks that every step calls a registered tool from
+// StepTools.
+func validateSteps(steps []Step) error {
+	for _, step := range steps {
+		if !slices.Contains(StepTools, step.Tool) {
+			return fmt.Errorf("jobs can't call %q, use one of: %s", step.Tool, strings.Join(StepTools, ", "))
+		}
+		if !HasTool(step.Tool) {
+			return fmt.Errorf("unknown tool %q in job steps", step.Tool)
+		}
+	}
+	return nil
+}
+
+func findJobLocked(id string) *Job {
+	for _, job := range jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// runJobs runs due jobs until ctx is cancelled.
+func runJobs(ctx context.Context) {
+	for {
+		wait := time.Hour
+		if next := nextJobRun(); !next.IsZero() {
+			wait = time.Until(next)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-jobsChanged:
+			timer.Stop()
+			continue
+		case <-timer.C:
+		}
+
+		for _, job := range dueJobs(time.Now()) {
+			runJob(ctx, job)
+		}
+	}
+}
+
+func nextJobRun() time.Time {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	var next time.Time
+	for _, job := range jobs {
+		if job.Paused || job.NextRun.IsZero() {
+			continue
+		}
+		if next.IsZero() || job.NextRun.Before(next) {
+			next = job.NextRun
+		}
+	}
+
+	return next
+}
+
+// dueJobs returns copies of the jobs due at now and moves them on to their
+// next run.
+func dueJobs(now time.Time) []Job {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	var due []Job
+	for _, job := range jobs {
+		if job.Paused || job.NextRun.IsZero() || job.NextRun.After(now) {
+			continue
+		}
+
+		due = append(due, *job)
+
+		schedule, err := ParseCron(job.Cron)
+		if err != nil {
+			job.NextRun = time.Time{}
+			continue
+		}
+		job.NextRun = schedule.Next(now)
+	}
+
+	return due
+}
+
+func runJob(ctx context.Context, job Job) {
+	runCtx, cancel := context.WithTimeout(ctx, jobTimeout)
+	defer cancel()
+
+	run := JobRun{StartedAt: time.Now(), Status: StatusDone}
+
+	// Steps are checked again in case jobs.json was edited by hand.
+	output, err := "", validateSteps(job.Steps)
+	if err == nil {
+		output, err = RunSteps(runCtx, job.Steps)
+	}
+	run.Output = output
+	if err != nil {
+		log.Printf("Job %s (%s) failed: %v", job.ID, job.Name, err)
+		run.Status = StatusFailed
+		run.Error = err.Error()
+	}
+
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	saved := findJobLocked(job.ID)
+	if saved == nil {
+		return
+	}
+
+	saved.History = append(saved.History, run)
+	if len(saved.History) > maxJobHistory {
+		saved.History = saved.History[len(saved.History)-maxJobHistory:]
+	}
+
+	if err := saveJobsLocked(); err != nil {
+		log.Printf("Failed to save job history: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	TriggerAt           = "at"
+	TriggerEndOfTrack   = "end_of_track"
+	TriggerEndOfContext = "end_of_context"
+
+	StatusPending   = "pending"
+	StatusDone      = "done"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+
+	// maxFinished is how many completed actions are kept for listing.
+	maxFinished = 20
+	// actionTimeout bounds how long the tool calls of one action may take.
+	actionTimeout = 30 * time.Second
+)
+
+var (
+	schedulerCtx    context.Context
+	schedulerCancel context.CancelFunc
+	schedulerWg     sync.WaitGroup
+	schedulerMutex  sync.Mutex
+
+	pending  = map[string]*ScheduledAction{}
+	finished []ScheduledAction
+	nextID   int
+)
+
+// Trigger describes when a scheduled action runs.
+type Trigger struct {
+	Kind string
+	// At is the wall-clock time for TriggerAt.
+	At time.Time
+	// Reference names the track or context being watched by the end of
+	// track and end of context triggers.
+	Reference string
+}
+
+func (t Trigger) String() string {
+	switch t.Kind {
+	case TriggerAt:
+		return "at " + t.At.Format("2006-01-02 15:04:05")
+	case TriggerEndOfTrack:
+		return "when the current track ends (" + t.Reference + ")"
+	case TriggerEndOfContext:
+		return "when the current context ends (" + t.Reference + ")"
+	default:
+		return t.Kind
+	}
+}
+
+// ScheduledAction is an action waiting for, or finished after, its trigger.
+type ScheduledAction struct {
+	ID         string
+	Action     Action
+	Trigger    Trigger
+	CreatedAt  time.Time
+	Status     string
+	FinishedAt time.Time
+	Result     string
+
+	cancel context.CancelFunc
+}
+
+// Start runs the scheduler and the saved jobs until ctx is cancelled or Stop
+// is called.
+func Start(ctx context.Context) {
+	schedulerMutex.Lock()
+	defer schedulerMutex.Unlock()
+
+	schedulerCtx, schedulerCancel = context.WithCancel(ctx)
+
+	if err := loadJobs(); err != nil {
+		log.Printf("Failed to load jobs: %v", err)
+	}
+
+	schedulerWg.Add(1)
+	go func() {
+		defer schedulerWg.Done()
+		runJobs(schedulerCtx)
+	}()
+}
+
+// Stop cancels every pending action and job run, and waits for their
+// goroutines to exit.
+func Stop() {
+	schedulerMutex.Lock()
+	if schedulerCancel != nil {
+		schedulerCancel()
+	}
+	schedulerMutex.Unlock()
+
+	schedulerWg.Wait()
+}
+
+// Schedule validates action and arranges for it to run when trigger fires.
+// ctx is only used to inspect the current playback; the action itself runs on
+// the scheduler's own context so it outlives the tool call that created it.
+func Schedule(ctx context.Context, action Action, trigger Trigger) (ScheduledAction, error) {
+	if err := action.Validate(); err != nil {
+		return ScheduledAction{}, err
+	}
+
+	wait, trigger, err := prepareTrigger(ctx, trigger)
+	if err != nil {
+		return ScheduledAction{}, err
+	}
+
+	schedulerMutex.Lock()
+	defer schedulerMutex.Unlock()
+
+	if schedulerCtx == nil || schedulerCtx.Err() != nil {
+		return ScheduledAction{}, errors.New("the scheduler is not running")
+	}
+
+	nextID++
+	actionCtx, cancel := context.WithCancel(schedulerCtx)
+	scheduled := &ScheduledAction{
+		ID:        fmt.Sprintf("action-%d", nextID),
+		Action:    action,
+		Trigger:   trigger,
+		CreatedAt: time.Now(),
+		Status:    StatusPending,
+		cancel:    cancel,
+	}
+	pending[scheduled.ID] = scheduled
+
+	schedulerWg.Add(1)
+	go run(actionCtx, scheduled, wait)
+
+	return *scheduled, nil
+}
+
+// prepareTrigger returns a function that blocks until trigger fires. Playback
+// based triggers capture what is playing now, so they fail early if nothing is.
+func prepareTrigger(ctx context.Context, trigger Trigger) (func(ctx context.Context) error, Trigger, error) {
+	switch trigger.Kind {
+	case TriggerAt:
+		if !trigger.At.After(time.Now()) {
+			return nil, trigger, fmt.Errorf("%s is in the past", trigger.At.Format(time.RFC3339))
+		}
+		at := trigger.At
+		return func(ctx context.Context) error {
+			timer := time.NewTimer(time.Until(at))
+			defer timer.Stop()
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+				return nil
+			}
+		}, trigger, nil
+	case TriggerEndOfTrack:
+		trackID, name, err := currentTrack(ctx)
+		if err != nil {
+			return nil, trigger, err
+		}
+		trigger.Reference = name
+		return func(ctx context.Context) error {
+			return waitForEndOfTrack(ctx, trackID)
+		}, trigger, nil
+	case TriggerEndOfContext:
+		watch, err := currentContext(ctx)
+		if err != nil {
+			return nil, trigger, err
+		}
+		trigger.Reference = string(watch.uri)
+		return func(ctx context.Context) error {
+			return waitForEndOfContext(ctx, watch)
+		}, trigger, nil
+	default:
+		return nil, trigger, fmt.Errorf("unknown trigger %q", trigger.Kind)
+	}
+}
+
+func run(ctx context.Context, scheduled *ScheduledAction, wait func(ctx context.Context) error) {
+	defer schedulerWg.Done()
+	defer scheduled.cancel()
+
+	if err := wait(ctx); err != nil {
+		status := StatusFailed
+		if ctx.Err() != nil {
+			status = StatusCancelled
+		}
+		finish(scheduled, status, err.Error())
+		return
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, actionTimeout)
+	defer cancel()
+
+	output, err := RunSteps(runCtx, scheduled.Action.Steps())
+	if err != nil {
+		log.Printf("Scheduled action %s failed: %v", scheduled.ID, err)
+		finish(scheduled, StatusFailed, err.Error())
+		return
+	}
+
+	finish(scheduled, StatusDone, output)
+}
+
+func finish(scheduled *ScheduledAction, status string, result string) {
+	schedulerMutex.Lock()
+	defer schedulerMutex.Unlock()
+
+	if _, ok := pending[scheduled.ID]; !ok {
+		return
+	}
+	delete(pending, scheduled.ID)
+
+	scheduled.Status = status
+	scheduled.Result = result
+	scheduled.FinishedAt = time.Now()
+
+	finished = append(finished, *scheduled)
+	if len(finished) > maxFinished {
+		finished = finished[len(finished)-maxFinished:]
+	}
+}
+
+// Cancel cancels a pending action.
+func Cancel(id string) (ScheduledAction, error) {
+	schedulerMutex.Lock()
+	scheduled, ok := pending[id]
+	schedulerMutex.Unlock()
+
+	if !ok {
+		return ScheduledAction{}, fmt.Errorf("no pending action with ID %q", id)
+	}
+
+	finish(scheduled, StatusCancelled, "cancelled")
+	scheduled.cancel()
+
+	return *scheduled, nil
+}
+
+// List returns the pending actions in the order they were scheduled, and the
+// recently finished actions, most recent last.
+func List() ([]ScheduledAction, []ScheduledAction) {
+	schedulerMutex.Lock()
+	defer schedulerMutex.Unlock()
+
+	pendingActions := make([]ScheduledAction, 0, len(pending))
+	for _, scheduled := range pending {
+		pendingActions = append(pendingActions, *scheduled)
+	}
+
+	sort.Slice(pendingActions, func(i, j int) bool {
+		return pendingActions[i].CreatedAt.Before(pendingActions[j].CreatedAt)
+	})
+
+	finishedActions := make([]ScheduledAction, len(finished))
+	copy(finishedActions, finished)
+
+	return pendingActions, finishedActions
+}
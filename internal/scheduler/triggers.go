@@ -0,0 +1,199 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+)
+
+const (
+	// pollInterval is the longest gap between playback checks, so skips and
+	// seeks are noticed reasonably quickly.
+	pollInterval = 15 * time.Second
+	// endOfTrackMargin is how long before the end of a track the action
+	// fires, so a pause lands before the next track starts.
+	endOfTrackMargin = 700 * time.Millisecond
+)
+
+// contextWatch is the playback context an end of context trigger waits on.
+type contextWatch struct {
+	uri spotify.URI
+	// lastItemID is the final track of an album or playlist context, if known.
+	lastItemID spotify.ID
+}
+
+func currentTrack(ctx context.Context) (spotify.ID, string, error) {
+	if !client.IsPlaybackAuthenticated() {
+		return "", "", errors.New("not authenticated with Spotify, use the spotify_login tool first")
+	}
+
+	playing, err := client.AuthenticatedSpotifyClient.PlayerCurrentlyPlaying(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get currently playing track: %w", err)
+	}
+
+	if playing == nil || playing.Item == nil {
+		return "", "", errors.New("nothing is currently playing")
+	}
+
+	return playing.Item.ID, playing.Item.Name, nil
+}
+
+func currentContext(ctx context.Context) (contextWatch, error) {
+	if !client.IsPlaybackAuthenticated() {
+		return contextWatch{}, errors.New("not authenticated with Spotify, use the spotify_login tool first")
+	}
+
+	playing, err := client.AuthenticatedSpotifyClient.PlayerCurrentlyPlaying(ctx)
+	if err != nil {
+		return contextWatch{}, fmt.Errorf("failed to get currently playing track: %w", err)
+	}
+
+	if playing == nil || playing.Item == nil {
+		return contextWatch{}, errors.New("nothing is currently playing")
+	}
+
+	if playing.PlaybackContext.URI == "" {
+		return contextWatch{}, errors.New("the current track is not playing from an album, playlist or artist, use end_of_track instead")
+	}
+
+	watch := contextWatch{uri: playing.PlaybackContext.URI}
+
+	lastItemID, err := lastItemInContext(ctx, playing.PlaybackContext)
+	if err != nil {
+		log.Printf("Could not find the last track of %s, waiting for the context to change instead: %v", watch.uri, err)
+	}
+	watch.lastItemID = lastItemID
+
+	return watch, nil
+}
+
+// lastItemInContext returns the final track of an album or playlist. Other
+// contexts have no fixed end, so an empty ID is returned for them.
+func lastItemInContext(ctx context.Context, playbackContext spotify.PlaybackContext) (spotify.ID, error) {
+	parts := strings.Split(string(playbackContext.URI), ":")
+	id := spotify.ID(parts[len(parts)-1])
+
+	switch playbackContext.Type {
+	case "album":
+		page, err := client.AuthenticatedSpotifyClient.GetAlbumTracks(ctx, id, spotify.Limit(1))
+		if err != nil {
+			return "", err
+		}
+		if page.Total == 0 {
+			return "", nil
+		}
+		page, err = client.AuthenticatedSpotifyClient.GetAlbumTracks(ctx, id, spotify.Limit(1), spotify.Offset(int(page.Total)-1))
+		if err != nil || len(page.Tracks) == 0 {
+			return "", err
+		}
+		return page.Tracks[0].ID, nil
+	case "playlist":
+		page, err := client.AuthenticatedSpotifyClient.GetPlaylistItems(ctx, id, spotify.Limit(1))
+		if err != nil {
+			return "", err
+		}
+		if page.Total == 0 {
+			return "", nil
+		}
+		page, err = client.AuthenticatedSpotifyClient.GetPlaylistItems(ctx, id, spotify.Limit(1), spotify.Offset(int(page.Total)-1))
+		if err != nil || len(page.Items) == 0 {
+			return "", err
+		}
+		item := page.Items[0].Track
+		if item.Track != nil {
+			return item.Track.ID, nil
+		}
+		if item.Episode != nil {
+			return item.Episode.ID, nil
+		}
+		return "", nil
+	default:
+		return "", nil
+	}
+}
+
+// waitForEndOfTrack blocks until trackID has finished playing, was skipped or
+// playback moved on to something else.
+func waitForEndOfTrack(ctx context.Context, trackID spotify.ID) error {
+	for {
+		playing, err := client.AuthenticatedSpotifyClient.PlayerCurrentlyPlaying(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("Scheduler: failed to check playback: %v", err)
+			if err := sleep(ctx, pollInterval); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if playing == nil || playing.Item == nil || playing.Item.ID != trackID {
+			return nil
+		}
+
+		if playing.Playing {
+			remaining := time.Duration(playing.Item.Duration-playing.Progress) * time.Millisecond
+			if remaining <= pollInterval {
+				return sleep(ctx, remaining-endOfTrackMargin)
+			}
+		}
+
+		if err := sleep(ctx, pollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// waitForEndOfContext blocks until playback leaves the watched context, or its
+// last track finishes when the last track is known and shuffle is off.
+func waitForEndOfContext(ctx context.Context, watch contextWatch) error {
+	for {
+		state, err := client.AuthenticatedSpotifyClient.PlayerState(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("Scheduler: failed to check playback: %v", err)
+			if err := sleep(ctx, pollInterval); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if state == nil || state.Item == nil || state.PlaybackContext.URI != watch.uri {
+			return nil
+		}
+
+		if watch.lastItemID != "" && !state.ShuffleState && state.RepeatState != "context" && state.Item.ID == watch.lastItemID {
+			return waitForEndOfTrack(ctx, watch.lastItemID)
+		}
+
+		if err := sleep(ctx, pollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
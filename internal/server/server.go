@@ -4,9 +4,15 @@ import (
 	"context"
 	mcpServer "github.com/mark3labs/mcp-go/server"
 	"os"
+	"os/signal"
+	"spotify-mcp/internal/history"
+	"spotify-mcp/internal/scheduler"
+	historyTools "spotify-mcp/internal/server/tools/history"
 	"spotify-mcp/internal/server/tools/playback"
 	"spotify-mcp/internal/server/tools/playlist"
+	"spotify-mcp/internal/server/tools/schedule"
 	"spotify-mcp/internal/server/tools/search"
+	"syscall"
 )
 
 func StartMcpServer() {
@@ -19,10 +25,26 @@ func StartMcpServer() {
 	tools = append(tools, playback.PlayerTools()...)
 	tools = append(tools, playlist.PlaylistTools()...)
 	tools = append(tools, playback.QueueTools()...)
+	tools = append(tools, playback.DeviceTools()...)
+	tools = append(tools, schedule.ScheduleTools()...)
+	tools = append(tools, schedule.JobTools()...)
+	tools = append(tools, historyTools.HistoryTools()...)
 	for _, tool := range tools {
 		s.AddTool(tool.ToolDefinition, tool.ToolBehaviour)
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	scheduler.RegisterTools(tools)
+	scheduler.Start(ctx)
+	defer scheduler.Stop()
+
+	if history.RecorderEnabled() {
+		history.StartRecorder(ctx)
+		defer history.StopRecorder()
+	}
+
 	sseServer := mcpServer.NewStdioServer(s)
-	sseServer.Listen(context.Background(), os.Stdin, os.Stdout)
+	sseServer.Listen(ctx, os.Stdin, os.Stdout)
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
 )
 
 func GetParamFromRequest(request mcp.CallToolRequest, paramToSearchFor string) (string, error) {
@@ -39,10 +40,31 @@ func GetIntParamFromRequest(request mcp.CallToolRequest, paramName string) (int,
 	if !ok {
 		return 0, fmt.Errorf("parameter %s not provided", paramName)
 	}
-	intVal, ok := param.(int)
-	if !ok {
+
+	// JSON numbers are decoded as float64, so accept those as well.
+	switch value := param.(type) {
+	case int:
+		return value, nil
+	case float64:
+		if value != float64(int(value)) {
+			return 0, fmt.Errorf("parameter %s is not an integer", paramName)
+		}
+		return int(value), nil
+	default:
 		return 0, fmt.Errorf("parameter %s is not an integer", paramName)
 	}
+}
+
+// SplitCommaSeparated splits a comma-separated parameter value, trimming
+// whitespace and dropping empty entries.
+func SplitCommaSeparated(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			values = append(values, part)
+		}
+	}
 
-	return intVal, nil
+	return values
 }
@@ -0,0 +1,203 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"spotify-mcp/internal/history"
+	"spotify-mcp/internal/server/tools"
+	"spotify-mcp/internal/timeparse"
+)
+
+const defaultHistoryLimit = 50
+
+func HistoryTools() []tools.ToolEntry {
+	return []tools.ToolEntry{
+		listeningHistoryTool(),
+		listeningStatsTool(),
+		importStreamingHistoryTool(),
+	}
+}
+
+func listeningHistoryTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"listening_history",
+		mcp.WithDescription("Search the local listening history, newest first. The history is recorded while the server runs with SPOTIFY_HISTORY_RECORDER enabled, or imported with import_streaming_history"),
+		mcp.WithString("from",
+			mcp.Description("Only events after this time, e.g. \"yesterday\", \"3 days ago\" or \"2006-01-02\""),
+		),
+		mcp.WithString("to",
+			mcp.Description("Only events before this time, in the same forms as from"),
+		),
+		mcp.WithString("artist",
+			mcp.Description("Only tracks by an artist whose name contains this"),
+		),
+		mcp.WithString("track",
+			mcp.Description("Only tracks whose name contains this"),
+		),
+		mcp.WithBoolean("all_events",
+			mcp.Description("Include play, pause and resume events instead of only finished and skipped tracks (default: false)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of events to return (default: 50)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  listeningHistoryBehaviour,
+	}
+}
+
+func listeningHistoryBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filter, err := filterFromRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	allEvents, _ := tools.GetBoolParamFromRequest(request, "all_events")
+	filter.ListensOnly = !allEvents
+
+	limit, err := tools.GetIntParamFromRequest(request, "limit")
+	if err != nil || limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+
+	// Keep only the newest events, as a ring of the last limit matches.
+	var events []history.Event
+	total := 0
+	err = history.Read(filter, func(event history.Event) {
+		total++
+		events = append(events, event)
+		if len(events) > limit {
+			events = events[1:]
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read listening history: %w", err)
+	}
+
+	if total == 0 {
+		response := "No listening history matches."
+		if !history.RecorderEnabled() {
+			response += " The recorder is off; set SPOTIFY_HISTORY_RECORDER=true to record history."
+		}
+		return mcp.NewToolResultText(response), nil
+	}
+
+	response := fmt.Sprintf("Listening history (%d of %d matching event(s), newest first):\n\n", len(events), total)
+	for i := len(events) - 1; i >= 0; i-- {
+		response += formatEvent(events[i]) + "\n"
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// filterFromRequest reads the from, to, artist and track parameters shared by
+// the history tools.
+func filterFromRequest(request mcp.CallToolRequest) (history.Filter, error) {
+	var filter history.Filter
+	now := time.Now()
+
+	if value, _ := tools.GetParamFromRequest(request, "from"); strings.TrimSpace(value) != "" {
+		from, err := timeparse.ParsePastTime(value, now)
+		if err != nil {
+			return history.Filter{}, fmt.Errorf("invalid from: %w", err)
+		}
+		filter.From = from
+	}
+
+	if value, _ := tools.GetParamFromRequest(request, "to"); strings.TrimSpace(value) != "" {
+		to, err := timeparse.ParsePastTime(value, now)
+		if err != nil {
+			return history.Filter{}, fmt.Errorf("invalid to: %w", err)
+		}
+		filter.To = to
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return history.Filter{}, fmt.Errorf("from must be earlier than to")
+	}
+
+	filter.Artist, _ = tools.GetParamFromRequest(request, "artist")
+	filter.Track, _ = tools.GetParamFromRequest(request, "track")
+
+	return filter, nil
+}
+
+func formatEvent(event history.Event) string {
+	line := fmt.Sprintf("- %s %s: %s", event.Time.Local().Format("2006-01-02 15:04"), event.Type, event.Track)
+	if len(event.Artists) > 0 {
+		line += " by " + strings.Join(event.Artists, ", ")
+	}
+
+	if event.IsListen() && event.DurationMs > 0 {
+		line += fmt.Sprintf(" (played %s of %s)", formatMs(event.PlayedMs), formatMs(event.DurationMs))
+	} else if event.IsListen() && event.PlayedMs > 0 {
+		line += fmt.Sprintf(" (played %s)", formatMs(event.PlayedMs))
+	}
+
+	if event.TrackID != "" {
+		line += fmt.Sprintf(" [%s]", event.TrackID)
+	}
+
+	return line
+}
+
+func formatMs(ms int) string {
+	seconds := ms / 1000
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+func importStreamingHistoryTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"import_streaming_history",
+		mcp.WithDescription("Import Spotify's extended streaming history export (Streaming_History_Audio_*.json files) into the local history used by listening_history and listening_stats. Importing the same files again is safe"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Comma-separated paths of export files, or of the folder containing them"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  importStreamingHistoryBehaviour,
+	}
+}
+
+func importStreamingHistoryBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pathParam, err := tools.GetParamFromRequest(request, "path")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get path parameter: %w", err)
+	}
+
+	result, err := history.ImportStreamingHistory(tools.SplitCommaSeparated(pathParam))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not import the streaming history: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(FormatImportResult(result)), nil
+}
+
+// FormatImportResult describes an import, for the tool and the command line.
+func FormatImportResult(result history.ImportResult) string {
+	response := fmt.Sprintf("Read %d file(s).\n", len(result.Files))
+	response += fmt.Sprintf("Imported %d play(s)", result.Imported)
+	if result.Imported > 0 {
+		response += fmt.Sprintf(" from %s to %s", result.From.Local().Format("2006-01-02"), result.To.Local().Format("2006-01-02"))
+	}
+	response += ".\n"
+	if result.Duplicates > 0 {
+		response += fmt.Sprintf("Skipped %d play(s) imported before.\n", result.Duplicates)
+	}
+	if result.Skipped > 0 {
+		response += fmt.Sprintf("Skipped %d podcast episode(s) or record(s) without a track.\n", result.Skipped)
+	}
+	return response
+}
@@ -0,0 +1,148 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"spotify-mcp/internal/history"
+	"spotify-mcp/internal/server/tools"
+)
+
+const defaultStatsTop = 10
+
+func listeningStatsTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"listening_stats",
+		mcp.WithDescription("Summarize the local listening history for a period: total time, top tracks, artists and albums with track IDs, skip rate, hour-of-day and weekday patterns and listening streaks"),
+		mcp.WithString("from",
+			mcp.Description("Start of the period, e.g. \"30 days ago\", \"2023-01-01\" (default: all history)"),
+		),
+		mcp.WithString("to",
+			mcp.Description("End of the period, in the same forms as from (default: now)"),
+		),
+		mcp.WithString("artist",
+			mcp.Description("Only count tracks by an artist whose name contains this"),
+		),
+		mcp.WithNumber("top",
+			mcp.Description("Number of entries in each top list (default: 10)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  listeningStatsBehaviour,
+	}
+}
+
+func listeningStatsBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filter, err := filterFromRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	filter.ListensOnly = true
+
+	top, err := tools.GetIntParamFromRequest(request, "top")
+	if err != nil || top <= 0 {
+		top = defaultStatsTop
+	}
+
+	var events []history.Event
+	err = history.Read(filter, func(event history.Event) {
+		events = append(events, event)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read listening history: %w", err)
+	}
+
+	if len(events) == 0 {
+		return mcp.NewToolResultText("No listening history for that period. Import your Spotify streaming history with import_streaming_history, or enable the recorder with SPOTIFY_HISTORY_RECORDER=true."), nil
+	}
+
+	stats := history.ComputeStats(events, top, time.Now(), time.Local)
+
+	return mcp.NewToolResultText(formatStats(stats)), nil
+}
+
+func formatStats(stats history.Stats) string {
+	response := fmt.Sprintf("Listening stats from %s to %s\n\n", stats.From.Local().Format("2006-01-02"), stats.To.Local().Format("2006-01-02"))
+	response += fmt.Sprintf("Total listening time: %s\n", formatHours(stats.TotalMs))
+	response += fmt.Sprintf("Streams (30s or more): %d of %d plays, %d different tracks\n", stats.Streams, stats.Listens, stats.Distinct)
+	response += fmt.Sprintf("Skip rate: %.1f%%\n", 100*stats.SkipRate())
+	response += fmt.Sprintf("Days listened: %d\n", stats.Days)
+	if stats.Longest.Days > 0 {
+		response += fmt.Sprintf("Longest streak: %d day(s), %s to %s\n", stats.Longest.Days, stats.Longest.Start.Format("2006-01-02"), stats.Longest.End.Format("2006-01-02"))
+	}
+	response += fmt.Sprintf("Current streak: %d day(s)\n", stats.Current.Days)
+
+	response += "\nTop tracks:\n"
+	var trackIDs []string
+	for i, track := range stats.Tracks {
+		response += fmt.Sprintf("%d. %s by %s - %d streams, %s", i+1, track.Name, strings.Join(track.Artists, ", "), track.Streams, formatHours(track.Ms))
+		if len(track.TrackIDs) > 0 {
+			response += fmt.Sprintf(" [%s]", track.TrackIDs[0])
+			trackIDs = append(trackIDs, track.TrackIDs[0])
+		}
+		response += "\n"
+	}
+	if len(trackIDs) > 0 {
+		response += fmt.Sprintf("Top track IDs: %s\n", strings.Join(trackIDs, ","))
+	}
+
+	response += "\nTop artists:\n"
+	for i, artist := range stats.Artists {
+		response += fmt.Sprintf("%d. %s - %d streams, %s%s\n", i+1, artist.Name, artist.Streams, formatHours(artist.Ms), formatTopTrackIDs(artist.TrackIDs))
+	}
+
+	response += "\nTop albums:\n"
+	for i, album := range stats.Albums {
+		response += fmt.Sprintf("%d. %s by %s - %d streams, %s%s\n", i+1, album.Name, strings.Join(album.Artists, ", "), album.Streams, formatHours(album.Ms), formatTopTrackIDs(album.TrackIDs))
+	}
+
+	response += "\nListening by hour of day:\n"
+	response += formatDistribution(stats.ByHour[:], func(i int) string { return fmt.Sprintf("%02d:00", i) })
+
+	response += "\nListening by weekday:\n"
+	response += formatDistribution(stats.ByDay[:], func(i int) string { return time.Weekday(i).String()[:3] })
+
+	return response
+}
+
+func formatTopTrackIDs(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (top tracks: %s)", strings.Join(ids, ","))
+}
+
+// formatDistribution lists each bucket's share of the total listening time,
+// leaving out empty buckets.
+func formatDistribution(buckets []int64, label func(int) string) string {
+	var total int64
+	for _, ms := range buckets {
+		total += ms
+	}
+	if total == 0 {
+		return "No listening time.\n"
+	}
+
+	var response string
+	for i, ms := range buckets {
+		if ms == 0 {
+			continue
+		}
+		share := float64(ms) / float64(total)
+		response += fmt.Sprintf("%s %5.1f%% %s\n", label(i), 100*share, strings.Repeat("#", int(share*50+0.5)))
+	}
+	return response
+}
+
+func formatHours(ms int64) string {
+	minutes := ms / 60_000
+	if minutes < 60 {
+		return fmt.Sprintf("%d min", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
@@ -0,0 +1,255 @@
+package playback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/storage"
+)
+
+const (
+	devicePreferenceEnv = "SPOTIFY_DEVICE_PREFERENCE"
+	preferredDeviceEnv  = "SPOTIFY_PREFERRED_DEVICE"
+
+	preferLastUsed      = "last_used"
+	preferPreferredName = "preferred_name"
+	preferOnlyDevice    = "only_device"
+
+	// lastDeviceFile keeps the last used device across restarts.
+	lastDeviceFile = "last_device.json"
+)
+
+var (
+	lastUsedDeviceID spotify.ID
+	lastUsedLoaded   bool
+	lastUsedMutex    sync.Mutex
+)
+
+// lastDeviceRecord is the content of lastDeviceFile.
+type lastDeviceRecord struct {
+	DeviceID spotify.ID `json:"device_id"`
+}
+
+// errNoActiveDevice mirrors Spotify's NO_ACTIVE_DEVICE error, for commands
+// that find out there is no active device before calling Spotify.
+var errNoActiveDevice = spotify.Error{Message: "No active device found", Status: http.StatusNotFound}
+
+// activation describes a device that was activated automatically because
+// Spotify reported that no device was active.
+type activation struct {
+	device *spotify.PlayerDevice
+	reason string
+}
+
+// String is appended to tool responses so the caller knows which device was
+// chosen on their behalf.
+func (a *activation) String() string {
+	if a == nil {
+		return ""
+	}
+	return fmt.Sprintf("\nNo device was active, so playback was transferred to %s (%s).", a.device.Name, a.reason)
+}
+
+// isNoActiveDeviceError reports whether err is Spotify's NO_ACTIVE_DEVICE
+// error, returned by player commands when no device is currently active.
+func isNoActiveDeviceError(err error) bool {
+	var spotifyErr spotify.Error
+	if !errors.As(err, &spotifyErr) {
+		return false
+	}
+
+	if spotifyErr.Status != http.StatusNotFound {
+		return false
+	}
+
+	message := strings.ToLower(spotifyErr.Message)
+	return strings.Contains(message, "no active device") || strings.Contains(message, "no_active_device")
+}
+
+// rememberDevice records the device most recently used so it can be
+// reactivated later, including after the server restarts.
+func rememberDevice(deviceID spotify.ID) {
+	if deviceID == "" {
+		return
+	}
+
+	lastUsedMutex.Lock()
+	defer lastUsedMutex.Unlock()
+
+	lastUsedLoaded = true
+	if lastUsedDeviceID == deviceID {
+		return
+	}
+	lastUsedDeviceID = deviceID
+
+	if err := storage.WriteJSON(lastDeviceFile, lastDeviceRecord{DeviceID: deviceID}); err != nil {
+		log.Printf("Failed to save the last used device: %v", err)
+	}
+}
+
+// rememberTarget records the device a successful command targeted, if it
+// targeted one.
+func rememberTarget(opts *spotify.PlayOptions) {
+	if opts != nil && opts.DeviceID != nil {
+		rememberDevice(*opts.DeviceID)
+	}
+}
+
+func lastUsedDevice() spotify.ID {
+	lastUsedMutex.Lock()
+	defer lastUsedMutex.Unlock()
+
+	if !lastUsedLoaded {
+		lastUsedLoaded = true
+		var record lastDeviceRecord
+		if _, err := storage.ReadJSON(lastDeviceFile, &record); err != nil {
+			log.Printf("Failed to read the last used device: %v", err)
+		}
+		lastUsedDeviceID = record.DeviceID
+	}
+
+	return lastUsedDeviceID
+}
+
+// runWithDeviceActivation runs command with opts. If Spotify reports that no
+// device is active and no device was explicitly requested, a device is chosen
+// using the configured preferences, playback is transferred to it and command
+// is retried once against that device.
+func runWithDeviceActivation(
+	ctx context.Context,
+	opts *spotify.PlayOptions,
+	command func(opts *spotify.PlayOptions) error,
+) (*activation, error) {
+	err := command(opts)
+	if err == nil {
+		rememberTarget(opts)
+		return nil, nil
+	}
+	if !isNoActiveDeviceError(err) {
+		return nil, err
+	}
+
+	if opts != nil && opts.DeviceID != nil {
+		return nil, err
+	}
+
+	device, reason, chooseErr := chooseDevice(ctx)
+	if chooseErr != nil {
+		return nil, fmt.Errorf("%w (%v)", err, chooseErr)
+	}
+
+	err = client.AuthenticatedSpotifyClient.TransferPlayback(ctx, device.ID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer playback to %s: %w", device.Name, err)
+	}
+
+	retryOpts := &spotify.PlayOptions{}
+	if opts != nil {
+		*retryOpts = *opts
+	}
+	retryOpts.DeviceID = &device.ID
+
+	err = command(retryOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	rememberDevice(device.ID)
+
+	return &activation{device: device, reason: reason}, nil
+}
+
+// activatedOptions returns play options targeting the activated device, so that
+// follow-up commands in the same tool call go to the same place.
+func activatedOptions(opts *spotify.PlayOptions, activated *activation) *spotify.PlayOptions {
+	if activated == nil {
+		return opts
+	}
+
+	activatedOpts := &spotify.PlayOptions{}
+	if opts != nil {
+		*activatedOpts = *opts
+	}
+	activatedOpts.DeviceID = &activated.device.ID
+
+	return activatedOpts
+}
+
+// devicePreferences returns the order in which device selection strategies are
+// tried, as configured by SPOTIFY_DEVICE_PREFERENCE.
+func devicePreferences() []string {
+	configured := os.Getenv(devicePreferenceEnv)
+	if strings.TrimSpace(configured) == "" {
+		return []string{preferLastUsed, preferPreferredName, preferOnlyDevice}
+	}
+
+	var preferences []string
+	for _, preference := range strings.Split(configured, ",") {
+		preference = strings.ToLower(strings.TrimSpace(preference))
+		if preference != "" {
+			preferences = append(preferences, preference)
+		}
+	}
+
+	return preferences
+}
+
+// chooseDevice picks a device to activate, returning the device and a short
+// explanation of why it was chosen.
+func chooseDevice(ctx context.Context) (*spotify.PlayerDevice, string, error) {
+	allDevices, err := client.AuthenticatedSpotifyClient.PlayerDevices(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	var devices []spotify.PlayerDevice
+	for _, device := range allDevices {
+		if !device.Restricted {
+			devices = append(devices, device)
+		}
+	}
+
+	if len(devices) == 0 {
+		return nil, "", errors.New("no devices available, open Spotify on a phone, computer or speaker and try again")
+	}
+
+	for _, preference := range devicePreferences() {
+		switch preference {
+		case preferLastUsed:
+			lastUsed := lastUsedDevice()
+			for i := range devices {
+				if devices[i].ID == lastUsed {
+					return &devices[i], "last used device", nil
+				}
+			}
+		case preferPreferredName:
+			preferred := os.Getenv(preferredDeviceEnv)
+			if strings.TrimSpace(preferred) == "" {
+				continue
+			}
+			device, err := matchDevice(devices, preferred)
+			if err == nil {
+				return device, "preferred device", nil
+			}
+		case preferOnlyDevice:
+			if len(devices) == 1 {
+				return &devices[0], "only available device", nil
+			}
+		}
+	}
+
+	available := make([]*spotify.PlayerDevice, len(devices))
+	for i := range devices {
+		available[i] = &devices[i]
+	}
+
+	return nil, "", fmt.Errorf("no device is active and none could be chosen automatically, pass the device argument or use transfer_playback. Available devices: %s", deviceNames(available))
+}
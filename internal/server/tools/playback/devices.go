@@ -0,0 +1,227 @@
+package playback
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+)
+
+const deviceParameter = "device"
+
+func DeviceTools() []tools.ToolEntry {
+	return []tools.ToolEntry{
+		listDevicesTool(),
+		transferPlaybackTool(),
+	}
+}
+
+func listDevicesTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"list_devices",
+		mcp.WithDescription("List the devices available for Spotify playback"),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  listDevicesBehaviour,
+	}
+}
+
+func listDevicesBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+	}
+
+	devices, err := client.AuthenticatedSpotifyClient.PlayerDevices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	if len(devices) == 0 {
+		return mcp.NewToolResultText("No devices available. Open Spotify on a phone, computer or speaker and try again."), nil
+	}
+
+	response := fmt.Sprintf("Available devices (%d):\n\n", len(devices))
+	for i, device := range devices {
+		if device.Active {
+			rememberDevice(device.ID)
+		}
+
+		response += fmt.Sprintf("%d. %s (%s)\n", i+1, device.Name, device.Type)
+		response += fmt.Sprintf("   ID: %s\n", device.ID)
+		response += fmt.Sprintf("   Active: %t\n", device.Active)
+		response += fmt.Sprintf("   Volume: %d%%\n", device.Volume)
+		if device.Restricted {
+			response += "   Restricted: Yes (cannot be controlled through the Web API)\n"
+		}
+		response += "\n"
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func transferPlaybackTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"transfer_playback",
+		mcp.WithDescription("Transfer Spotify playback to another device"),
+		mcp.WithString(deviceParameter,
+			mcp.Required(),
+			mcp.Description("ID or name of the device to transfer playback to. Names are matched loosely, e.g. \"living room\""),
+		),
+		mcp.WithBoolean("play",
+			mcp.Description("Start playing on the new device after the transfer (default: false, keeps the current play state)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  transferPlaybackBehaviour,
+	}
+}
+
+func transferPlaybackBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+	}
+
+	deviceQuery, err := tools.GetParamFromRequest(request, deviceParameter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device parameter: %w", err)
+	}
+
+	play, _ := tools.GetBoolParamFromRequest(request, "play")
+
+	device, err := findDevice(ctx, deviceQuery)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	err = client.AuthenticatedSpotifyClient.TransferPlayback(ctx, device.ID, play)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer playback: %w", err)
+	}
+	rememberDevice(device.ID)
+
+	response := fmt.Sprintf("Playback transferred to %s (%s)", device.Name, device.Type)
+	if play {
+		response += " and started"
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// withDeviceParameter adds the optional device argument shared by the playback
+// and queue tools.
+func withDeviceParameter() mcp.ToolOption {
+	return mcp.WithString(deviceParameter,
+		mcp.Description("ID or name of the device to target (default: the currently active device)"),
+	)
+}
+
+// deviceOptionsFromRequest resolves the optional device argument into play
+// options. Both return values are nil when no device was requested, so the
+// command targets whichever device Spotify considers active.
+func deviceOptionsFromRequest(ctx context.Context, request mcp.CallToolRequest) (*spotify.PlayOptions, *spotify.PlayerDevice, error) {
+	deviceQuery, _ := tools.GetParamFromRequest(request, deviceParameter)
+	if strings.TrimSpace(deviceQuery) == "" {
+		return nil, nil, nil
+	}
+
+	device, err := findDevice(ctx, deviceQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &spotify.PlayOptions{DeviceID: &device.ID}, device, nil
+}
+
+// onDevice is appended to tool responses so the caller knows which device was
+// targeted.
+func onDevice(device *spotify.PlayerDevice) string {
+	if device == nil {
+		return ""
+	}
+	return fmt.Sprintf(" on %s", device.Name)
+}
+
+// findDevice fetches the available devices and matches query against them.
+func findDevice(ctx context.Context, query string) (*spotify.PlayerDevice, error) {
+	devices, err := client.AuthenticatedSpotifyClient.PlayerDevices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("no devices available. Open Spotify on a phone, computer or speaker and try again")
+	}
+
+	device, err := matchDevice(devices, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// matchDevice matches a device by exact ID, then by name: exact, prefix,
+// substring and finally by every word of the query appearing in the name.
+func matchDevice(devices []spotify.PlayerDevice, query string) (*spotify.PlayerDevice, error) {
+	query = strings.TrimSpace(query)
+	for i := range devices {
+		if string(devices[i].ID) == query {
+			return &devices[i], nil
+		}
+	}
+
+	normalisedQuery := strings.ToLower(query)
+	matchers := []func(name string) bool{
+		func(name string) bool { return name == normalisedQuery },
+		func(name string) bool { return strings.HasPrefix(name, normalisedQuery) },
+		func(name string) bool { return strings.Contains(name, normalisedQuery) },
+		func(name string) bool {
+			for _, word := range strings.Fields(normalisedQuery) {
+				if !strings.Contains(name, word) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+
+	for _, matches := range matchers {
+		var candidates []*spotify.PlayerDevice
+		for i := range devices {
+			if matches(strings.ToLower(devices[i].Name)) {
+				candidates = append(candidates, &devices[i])
+			}
+		}
+
+		if len(candidates) == 1 {
+			return candidates[0], nil
+		}
+
+		if len(candidates) > 1 {
+			return nil, fmt.Errorf("device %q is ambiguous, it matches: %s", query, deviceNames(candidates))
+		}
+	}
+
+	available := make([]*spotify.PlayerDevice, len(devices))
+	for i := range devices {
+		available[i] = &devices[i]
+	}
+
+	return nil, fmt.Errorf("no device matches %q. Available devices: %s", query, deviceNames(available))
+}
+
+func deviceNames(devices []*spotify.PlayerDevice) string {
+	names := make([]string, len(devices))
+	for i, device := range devices {
+		names[i] = fmt.Sprintf("%s (%s)", device.Name, device.ID)
+	}
+	return strings.Join(names, ", ")
+}
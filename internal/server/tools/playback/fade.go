@@ -0,0 +1,302 @@
+package playback
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+)
+
+const (
+	fadeStepInterval = time.Second
+	// fadeOverrideTolerance is how far, on top of one step, the device volume
+	// may drift from the levels the fade set before it is treated as a manual
+	// change. Spotify reports the volume with a delay, so it often still shows
+	// the level before the last one.
+	fadeOverrideTolerance = 2
+	// fadeOverrideChecks is how many checks in a row must find the volume off
+	// before the fade gives way to a manual change.
+	fadeOverrideChecks = 2
+)
+
+var (
+	activeFade *volumeFade
+	fadeMutex  sync.Mutex
+)
+
+// volumeFade is a volume ramp running in the background.
+type volumeFade struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// fadePlan describes a volume ramp.
+type fadePlan struct {
+	opts     *spotify.PlayOptions
+	from     int
+	to       int
+	duration time.Duration
+	// pause pauses playback once the target is reached.
+	pause bool
+	// restoreVolume sets the volume back to from after pausing, so the next
+	// playback does not start silently.
+	restoreVolume bool
+}
+
+func fadeVolumeTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"fade_volume",
+		mcp.WithDescription("Gradually change the volume to a target level over a number of seconds. Runs in the background; starting another fade or setting the volume cancels it"),
+		mcp.WithNumber("target",
+			mcp.Required(),
+			mcp.Description("Volume level to fade to, from 0 to 100"),
+		),
+		mcp.WithNumber("seconds",
+			mcp.Description("How long the fade should take in seconds (default: 10)"),
+		),
+		mcp.WithNumber("from",
+			mcp.Description("Volume level to start from (default: the current volume)"),
+		),
+		mcp.WithBoolean("pause",
+			mcp.Description("Pause playback once the target is reached (default: false)"),
+		),
+		mcp.WithBoolean("restore_volume",
+			mcp.Description("After pausing, restore the volume from before the fade so the next playback is not silent (default: true)"),
+		),
+		withDeviceParameter(),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  fadeVolumeBehaviour,
+	}
+}
+
+func fadeVolumeBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+	}
+
+	target, err := tools.GetIntParamFromRequest(request, "target")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target parameter: %w", err)
+	}
+
+	seconds, err := tools.GetIntParamFromRequest(request, "seconds")
+	if err != nil || seconds <= 0 {
+		seconds = 10
+	}
+
+	pause, _ := tools.GetBoolParamFromRequest(request, "pause")
+	restoreVolume, err := tools.GetBoolParamFromRequest(request, "restore_volume")
+	if err != nil {
+		restoreVolume = true
+	}
+
+	opts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	from, err := tools.GetIntParamFromRequest(request, "from")
+	if err != nil {
+		from, err = currentVolume(ctx, device)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	plan := fadePlan{
+		opts:          opts,
+		from:          clamp(from, 0, 100),
+		to:            clamp(target, 0, 100),
+		duration:      time.Duration(seconds) * time.Second,
+		pause:         pause,
+		restoreVolume: restoreVolume,
+	}
+
+	cancelled := startFade(plan)
+
+	response := fmt.Sprintf("Fading volume from %d%% to %d%% over %d seconds", plan.from, plan.to, seconds)
+	response += onDevice(device)
+	if pause {
+		response += ", then pausing"
+		if restoreVolume {
+			response += fmt.Sprintf(" and restoring the volume to %d%%", plan.from)
+		}
+	}
+	if cancelled {
+		response += ". The previous fade was cancelled"
+	}
+
+	return mcp.NewToolResultText(response + "."), nil
+}
+
+// startFade cancels any fade in progress and starts plan in the background.
+// It reports whether a previous fade was cancelled.
+func startFade(plan fadePlan) bool {
+	fadeMutex.Lock()
+	defer fadeMutex.Unlock()
+
+	cancelled := false
+	for activeFade != nil {
+		cancelled = stopFadeLocked() || cancelled
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fade := &volumeFade{cancel: cancel, done: make(chan struct{})}
+	activeFade = fade
+
+	go func() {
+		defer close(fade.done)
+		defer cancel()
+
+		runFade(ctx, plan)
+
+		fadeMutex.Lock()
+		if activeFade == fade {
+			activeFade = nil
+		}
+		fadeMutex.Unlock()
+	}()
+
+	return cancelled
+}
+
+// cancelFade stops the fade in progress, if any, and reports whether there was
+// one. It waits for the fade goroutine to exit so it can't overwrite a volume
+// change made right after.
+func cancelFade() bool {
+	fadeMutex.Lock()
+	defer fadeMutex.Unlock()
+
+	return stopFadeLocked()
+}
+
+func stopFadeLocked() bool {
+	if activeFade == nil {
+		return false
+	}
+
+	fade := activeFade
+	activeFade = nil
+	fade.cancel()
+
+	fadeMutex.Unlock()
+	<-fade.done
+	fadeMutex.Lock()
+
+	return true
+}
+
+func runFade(ctx context.Context, plan fadePlan) {
+	steps := int(plan.duration / fadeStepInterval)
+	if difference := abs(plan.to - plan.from); steps > difference {
+		steps = difference
+	}
+	if steps < 1 {
+		steps = 1
+	}
+
+	interval := plan.duration / time.Duration(steps)
+	tolerance := fadeOverrideTolerance + (abs(plan.to-plan.from)+steps-1)/steps
+	previousSet, lastSet := plan.from, plan.from
+	mismatches := 0
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for step := 1; step <= steps; step++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if step > 1 {
+			// The device may not have caught up with the last level yet, so
+			// anything between the last two levels set counts as unchanged.
+			volume, err := deviceVolume(ctx, plan.opts)
+			low, high := min(previousSet, lastSet)-tolerance, max(previousSet, lastSet)+tolerance
+			if err == nil && (volume < low || volume > high) {
+				mismatches++
+				if mismatches >= fadeOverrideChecks {
+					log.Printf("Volume fade stopped: volume was changed manually to %d%%", volume)
+					return
+				}
+				// Leave the volume alone this tick, so that the next check
+				// sees a manual change instead of the fade's own level.
+				continue
+			}
+			mismatches = 0
+		}
+
+		level := plan.from + (plan.to-plan.from)*step/steps
+		if err := client.AuthenticatedSpotifyClient.VolumeOpt(ctx, level, plan.opts); err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Volume fade stopped: failed to set volume: %v", err)
+			}
+			return
+		}
+		if step == 1 {
+			rememberTarget(plan.opts)
+		}
+		previousSet, lastSet = lastSet, level
+	}
+
+	// The last check found the volume changed, so the fade may no longer be
+	// wanted.
+	if mismatches > 0 {
+		log.Printf("Volume fade stopped: volume was changed manually before the end")
+		return
+	}
+
+	if !plan.pause {
+		return
+	}
+
+	if err := client.AuthenticatedSpotifyClient.PauseOpt(ctx, plan.opts); err != nil {
+		log.Printf("Volume fade: failed to pause playback: %v", err)
+		return
+	}
+
+	if plan.restoreVolume {
+		if err := client.AuthenticatedSpotifyClient.VolumeOpt(ctx, plan.from, plan.opts); err != nil {
+			log.Printf("Volume fade: failed to restore volume: %v", err)
+		}
+	}
+}
+
+// deviceVolume returns the volume of the device targeted by opts, or of the
+// active device.
+func deviceVolume(ctx context.Context, opts *spotify.PlayOptions) (int, error) {
+	devices, err := client.AuthenticatedSpotifyClient.PlayerDevices(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, device := range devices {
+		if opts != nil && opts.DeviceID != nil {
+			if device.ID == *opts.DeviceID {
+				return int(device.Volume), nil
+			}
+		} else if device.Active {
+			return int(device.Volume), nil
+		}
+	}
+
+	return 0, fmt.Errorf("device not found")
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
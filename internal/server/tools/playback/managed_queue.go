@@ -0,0 +1,521 @@
+package playback
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+)
+
+const (
+	// feedAhead is how many managed items are handed to Spotify before they
+	// play. Spotify can't remove queued items, so keeping this small keeps
+	// the rest of the queue editable.
+	feedAhead = 2
+	// queueWatchInterval is how often playback is checked while the managed
+	// queue has items.
+	queueWatchInterval = 5 * time.Second
+	// fedGracePeriod is how long a fed item is assumed to still be queued,
+	// since Spotify's queue can take a moment to show newly added items.
+	fedGracePeriod = 10 * time.Second
+	// maxTracksPerLookup is the most tracks GetTracks accepts at once.
+	maxTracksPerLookup = 50
+)
+
+var (
+	// pendingItems are managed items that haven't been sent to Spotify yet.
+	pendingItems []queueItem
+	// fedItems have been sent to Spotify and not played yet.
+	fedItems       []queueItem
+	queueMutex     sync.Mutex
+	queueWatching  bool
+	nextQueueKey   uint64
+	queueFeedMutex sync.Mutex
+)
+
+// queueItem is a track in the managed queue.
+type queueItem struct {
+	// key tells apart repeated additions of the same track.
+	key      uint64
+	id       spotify.ID
+	name     string
+	artists  string
+	album    string
+	duration int
+	fedAt    time.Time
+}
+
+func (q queueItem) String() string {
+	if q.artists == "" {
+		return q.name
+	}
+	return fmt.Sprintf("%s by %s", q.name, q.artists)
+}
+
+func managedQueueTools() []tools.ToolEntry {
+	return []tools.ToolEntry{
+		queueInsertNextTool(),
+		queueRemoveTool(),
+		queueMoveTool(),
+		queueClearTool(),
+	}
+}
+
+func queueInsertNextTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"queue_insert_next",
+		mcp.WithDescription("Add tracks to the front of the managed queue so they play next"),
+		mcp.WithString("track_ids",
+			mcp.Required(),
+			mcp.Description("Comma-separated list of Spotify track IDs, URIs or links"),
+		),
+		withDeviceParameter(),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  queueInsertNextBehaviour,
+	}
+}
+
+func queueInsertNextBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+	}
+
+	trackIDsParam, err := tools.GetParamFromRequest(request, "track_ids")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get track_ids parameter: %w", err)
+	}
+
+	var ids []spotify.ID
+	for _, value := range tools.SplitCommaSeparated(trackIDsParam) {
+		_, id, err := tools.ParseSpotifyURI(value, "track")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		return mcp.NewToolResultError("No track IDs were given."), nil
+	}
+
+	opts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	items, missing, err := lookupQueueItems(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up tracks: %w", err)
+	}
+
+	if len(items) == 0 {
+		return mcp.NewToolResultError("None of the tracks were found."), nil
+	}
+
+	addToManagedQueue(items, true)
+
+	response := fmt.Sprintf("%d track(s) will play next%s:\n", len(items), onDevice(device))
+	for _, item := range items {
+		response += fmt.Sprintf("- %s\n", item)
+	}
+
+	if len(missing) > 0 {
+		response += fmt.Sprintf("\nTracks not found: %s\n", joinIDs(missing))
+	}
+
+	if sent := countFedItems(); sent > 0 {
+		response += fmt.Sprintf("\n%d track(s) already sent to Spotify play before them.\n", sent)
+	}
+
+	failures, activated, err := feedManagedQueue(ctx, opts)
+	for _, failure := range failures {
+		response += fmt.Sprintf("\nFailed to queue %s\n", failure)
+	}
+	if err != nil {
+		response += fmt.Sprintf("\nThe tracks could not be sent to Spotify yet (%v). They stay in the managed queue.\n", err)
+	}
+	response += activated.String()
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func queueRemoveTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"queue_remove",
+		mcp.WithDescription("Remove tracks from the managed queue by position, as shown by get_queue"),
+		mcp.WithString("positions",
+			mcp.Required(),
+			mcp.Description("Comma-separated positions in the managed queue, e.g. \"1,3\""),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  queueRemoveBehaviour,
+	}
+}
+
+func queueRemoveBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	positionsParam, err := tools.GetParamFromRequest(request, "positions")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions parameter: %w", err)
+	}
+
+	var positions []int
+	for _, value := range tools.SplitCommaSeparated(positionsParam) {
+		position, err := strconv.Atoi(value)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid position %q.", value)), nil
+		}
+		positions = append(positions, position)
+	}
+
+	removed, err := removeFromManagedQueue(positions)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	response := fmt.Sprintf("Removed %d track(s) from the queue:\n", len(removed))
+	for _, item := range removed {
+		response += fmt.Sprintf("- %s\n", item)
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func queueMoveTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"queue_move",
+		mcp.WithDescription("Move a track to another position in the managed queue, as shown by get_queue"),
+		mcp.WithNumber("from",
+			mcp.Required(),
+			mcp.Description("Current position of the track"),
+		),
+		mcp.WithNumber("to",
+			mcp.Required(),
+			mcp.Description("Position to move the track to"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  queueMoveBehaviour,
+	}
+}
+
+func queueMoveBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	from, err := tools.GetIntParamFromRequest(request, "from")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get from parameter: %w", err)
+	}
+
+	to, err := tools.GetIntParamFromRequest(request, "to")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get to parameter: %w", err)
+	}
+
+	moved, err := moveInManagedQueue(from, to)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Moved %s from position %d to %d.", moved, from, to)), nil
+}
+
+func queueClearTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"queue_clear",
+		mcp.WithDescription("Remove every track from the managed queue"),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  queueClearBehaviour,
+	}
+}
+
+func queueClearBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	queueMutex.Lock()
+	cleared := len(pendingItems)
+	pendingItems = nil
+	sent := len(fedItems)
+	queueMutex.Unlock()
+
+	if cleared == 0 && sent == 0 {
+		return mcp.NewToolResultText("The managed queue is already empty."), nil
+	}
+
+	response := fmt.Sprintf("Removed %d track(s) from the queue.", cleared)
+	if sent > 0 {
+		response += fmt.Sprintf(" %d track(s) already sent to Spotify will still play, since Spotify doesn't allow removing them.", sent)
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// lookupQueueItems fetches the names of tracks for display. IDs that are not
+// tracks are returned as missing.
+func lookupQueueItems(ctx context.Context, ids []spotify.ID) ([]queueItem, []spotify.ID, error) {
+	var items []queueItem
+	var missing []spotify.ID
+	for start := 0; start < len(ids); start += maxTracksPerLookup {
+		end := start + maxTracksPerLookup
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		tracks, err := client.AuthenticatedSpotifyClient.GetTracks(ctx, ids[start:end])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for i, track := range tracks {
+			if track == nil {
+				missing = append(missing, ids[start+i])
+				continue
+			}
+			items = append(items, newQueueItem(track))
+		}
+	}
+
+	return items, missing, nil
+}
+
+func newQueueItem(track *spotify.FullTrack) queueItem {
+	var artists []string
+	for _, artist := range track.Artists {
+		artists = append(artists, artist.Name)
+	}
+
+	return queueItem{
+		id:       track.ID,
+		name:     track.Name,
+		artists:  strings.Join(artists, ", "),
+		album:    track.Album.Name,
+		duration: int(track.Duration),
+	}
+}
+
+// addToManagedQueue appends items, or puts them at the front when next is
+// set, and starts watching playback.
+func addToManagedQueue(items []queueItem, next bool) {
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	for i := range items {
+		nextQueueKey++
+		items[i].key = nextQueueKey
+	}
+
+	if next {
+		pendingItems = append(append([]queueItem{}, items...), pendingItems...)
+	} else {
+		pendingItems = append(pendingItems, items...)
+	}
+
+	if !queueWatching {
+		queueWatching = true
+		go watchManagedQueue()
+	}
+}
+
+// managedQueue returns the items sent to Spotify and those still pending.
+func managedQueue() ([]queueItem, []queueItem) {
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	return append([]queueItem{}, fedItems...), append([]queueItem{}, pendingItems...)
+}
+
+func countFedItems() int {
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	return len(fedItems)
+}
+
+func removeFromManagedQueue(positions []int) ([]queueItem, error) {
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	remove := map[int]bool{}
+	for _, position := range positions {
+		if err := checkQueuePositionLocked(position); err != nil {
+			return nil, err
+		}
+		remove[position-1] = true
+	}
+
+	var removed, kept []queueItem
+	for i, item := range pendingItems {
+		if remove[i] {
+			removed = append(removed, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	pendingItems = kept
+
+	return removed, nil
+}
+
+func moveInManagedQueue(from, to int) (queueItem, error) {
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	if err := checkQueuePositionLocked(from); err != nil {
+		return queueItem{}, err
+	}
+	if err := checkQueuePositionLocked(to); err != nil {
+		return queueItem{}, err
+	}
+
+	item := pendingItems[from-1]
+	pendingItems = append(pendingItems[:from-1], pendingItems[from:]...)
+	pendingItems = append(pendingItems[:to-1], append([]queueItem{item}, pendingItems[to-1:]...)...)
+
+	return item, nil
+}
+
+func checkQueuePositionLocked(position int) error {
+	if len(pendingItems) == 0 {
+		return fmt.Errorf("the managed queue is empty")
+	}
+	if position < 1 || position > len(pendingItems) {
+		return fmt.Errorf("position %d is outside the managed queue (1-%d)", position, len(pendingItems))
+	}
+	return nil
+}
+
+// feedManagedQueue sends pending items to Spotify until feedAhead of them are
+// waiting there. Items Spotify refuses are dropped and returned as failures.
+func feedManagedQueue(ctx context.Context, opts *spotify.PlayOptions) ([]queueFailure, *activation, error) {
+	queueFeedMutex.Lock()
+	defer queueFeedMutex.Unlock()
+
+	var failures []queueFailure
+	var activated *activation
+	for {
+		queueMutex.Lock()
+		if len(fedItems) >= feedAhead || len(pendingItems) == 0 {
+			queueMutex.Unlock()
+			return failures, activated, nil
+		}
+		item := pendingItems[0]
+		queueMutex.Unlock()
+
+		itemActivation, err := runWithDeviceActivation(ctx, opts, func(opts *spotify.PlayOptions) error {
+			return client.AuthenticatedSpotifyClient.QueueSongOpt(ctx, item.id, opts)
+		})
+		if itemActivation != nil {
+			activated = itemActivation
+			opts = activatedOptions(opts, activated)
+		}
+		if err != nil && !isItemError(err) {
+			return failures, activated, err
+		}
+
+		queueMutex.Lock()
+		// The item may have been moved or removed while it was being sent.
+		for i, pending := range pendingItems {
+			if pending.key == item.key {
+				pendingItems = append(pendingItems[:i], pendingItems[i+1:]...)
+				break
+			}
+		}
+		if err == nil {
+			item.fedAt = time.Now()
+			fedItems = append(fedItems, item)
+		}
+		queueMutex.Unlock()
+
+		if err != nil {
+			failures = append(failures, queueFailure{item: item.String(), reason: spotifyErrorReason(err)})
+		}
+	}
+}
+
+// watchManagedQueue follows playback while the managed queue has items,
+// sending the next items to Spotify as the earlier ones start playing.
+func watchManagedQueue() {
+	ctx := context.Background()
+	ticker := time.NewTicker(queueWatchInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if client.IsPlaybackAuthenticated() {
+			if err := syncFedItems(ctx); err != nil {
+				log.Printf("Failed to check the Spotify queue: %v", err)
+			} else {
+				failures, _, err := feedManagedQueue(ctx, nil)
+				for _, failure := range failures {
+					log.Printf("Dropped %s from the managed queue", failure)
+				}
+				if err != nil {
+					log.Printf("Failed to send managed queue items to Spotify: %v", err)
+				}
+			}
+		}
+
+		queueMutex.Lock()
+		if len(pendingItems) == 0 && len(fedItems) == 0 {
+			queueWatching = false
+			queueMutex.Unlock()
+			return
+		}
+		queueMutex.Unlock()
+	}
+}
+
+// syncFedItems forgets fed items that are no longer upcoming in Spotify's
+// queue, because they are playing or have been played or skipped.
+func syncFedItems(ctx context.Context) error {
+	queue, err := client.AuthenticatedSpotifyClient.GetQueue(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Without an active player the queue is empty, which says nothing about
+	// what has been played.
+	if queue.CurrentlyPlaying.ID == "" {
+		return nil
+	}
+
+	upcoming := map[spotify.ID]int{}
+	for _, track := range queue.Items {
+		upcoming[track.ID]++
+	}
+
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
+
+	var remaining []queueItem
+	for _, item := range fedItems {
+		if upcoming[item.id] > 0 || time.Since(item.fedAt) < fedGracePeriod {
+			upcoming[item.id]--
+			remaining = append(remaining, item)
+		}
+	}
+	fedItems = remaining
+
+	return nil
+}
+
+func joinIDs(ids []spotify.ID) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = string(id)
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,165 @@
+package playback
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/server/tools"
+)
+
+const (
+	contextURIParameter = "context_uri"
+	urisParameter       = "uris"
+	offsetParameter     = "offset"
+	positionMsParameter = "position_ms"
+)
+
+// playContextTypes are the item types Spotify accepts as a playback context.
+var playContextTypes = map[string]bool{
+	"album":    true,
+	"playlist": true,
+	"artist":   true,
+	"show":     true,
+}
+
+// playOptionsFromRequest builds the play options described by the context_uri,
+// uris, offset and position_ms arguments. It returns nil options when none of
+// them were given, which resumes the current playback.
+func playOptionsFromRequest(request mcp.CallToolRequest) (*spotify.PlayOptions, error) {
+	contextValue, _ := tools.GetParamFromRequest(request, contextURIParameter)
+	urisValue, _ := tools.GetParamFromRequest(request, urisParameter)
+	uriValues := tools.SplitCommaSeparated(urisValue)
+	contextValue = strings.TrimSpace(contextValue)
+
+	offsetValue, hasOffset := offsetFromRequest(request)
+
+	positionMs, err := tools.GetIntParamFromRequest(request, positionMsParameter)
+	hasPosition := err == nil
+
+	if contextValue == "" && len(uriValues) == 0 && !hasOffset && !hasPosition {
+		return nil, nil
+	}
+
+	if contextValue != "" && len(uriValues) > 0 {
+		return nil, errors.New("context_uri and uris can not be combined: play either a context (album, playlist, artist or show) or a list of tracks")
+	}
+
+	if hasOffset && contextValue == "" && len(uriValues) == 0 {
+		return nil, errors.New("offset requires either context_uri or uris")
+	}
+
+	if hasPosition && positionMs < 0 {
+		return nil, errors.New("position_ms must not be negative")
+	}
+
+	opts := &spotify.PlayOptions{}
+
+	if contextValue != "" {
+		contextURI, contextType, err := tools.NormalizeSpotifyURI(contextValue, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid context_uri: %w", err)
+		}
+
+		if !playContextTypes[contextType] {
+			return nil, fmt.Errorf("context_uri must be an album, playlist, artist or show, not a %s. Use uris to play individual tracks", contextType)
+		}
+
+		if hasOffset && (contextType == "artist" || contextType == "show") {
+			return nil, fmt.Errorf("offset is only supported for album and playlist contexts, not a %s", contextType)
+		}
+
+		opts.PlaybackContext = &contextURI
+	}
+
+	for _, value := range uriValues {
+		uri, itemType, err := tools.NormalizeSpotifyURI(value, "track")
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry in uris: %w", err)
+		}
+
+		if itemType != "track" && itemType != "episode" {
+			return nil, fmt.Errorf("uris may only contain tracks or episodes, %q is a %s. Use context_uri to play it", value, itemType)
+		}
+
+		opts.URIs = append(opts.URIs, uri)
+	}
+
+	if hasOffset {
+		if position, err := strconv.Atoi(offsetValue); err == nil {
+			if position < 0 {
+				return nil, errors.New("offset must not be negative")
+			}
+			if len(opts.URIs) > 0 && position >= len(opts.URIs) {
+				return nil, fmt.Errorf("offset %d is out of range for %d uris", position, len(opts.URIs))
+			}
+			opts.PlaybackOffset = &spotify.PlaybackOffset{Position: &position}
+		} else {
+			offsetURI, itemType, err := tools.NormalizeSpotifyURI(offsetValue, "track")
+			if err != nil {
+				return nil, fmt.Errorf("invalid offset: %w", err)
+			}
+			if itemType != "track" && itemType != "episode" {
+				return nil, fmt.Errorf("offset must be an index or a track URI, not a %s", itemType)
+			}
+			opts.PlaybackOffset = &spotify.PlaybackOffset{URI: offsetURI}
+		}
+	}
+
+	if hasPosition {
+		opts.PositionMs = spotify.Numeric(positionMs)
+	}
+
+	return opts, nil
+}
+
+// offsetFromRequest reads the offset argument, which may be sent either as a
+// number or as a string holding an index or track URI.
+func offsetFromRequest(request mcp.CallToolRequest) (string, bool) {
+	if position, err := tools.GetIntParamFromRequest(request, offsetParameter); err == nil {
+		return strconv.Itoa(position), true
+	}
+
+	value, err := tools.GetParamFromRequest(request, offsetParameter)
+	if err != nil || strings.TrimSpace(value) == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(value), true
+}
+
+// describePlayOptions summarises what playback was started, for tool responses.
+func describePlayOptions(opts *spotify.PlayOptions) string {
+	if opts == nil {
+		return "Playback started"
+	}
+
+	var description string
+	switch {
+	case opts.PlaybackContext != nil:
+		description = fmt.Sprintf("Playing %s", *opts.PlaybackContext)
+	case len(opts.URIs) == 1:
+		description = fmt.Sprintf("Playing %s", opts.URIs[0])
+	case len(opts.URIs) > 1:
+		description = fmt.Sprintf("Playing %d tracks", len(opts.URIs))
+	default:
+		description = "Playback started"
+	}
+
+	if opts.PlaybackOffset != nil {
+		if opts.PlaybackOffset.Position != nil {
+			description += fmt.Sprintf(" from item %d", *opts.PlaybackOffset.Position)
+		} else {
+			description += fmt.Sprintf(" from %s", opts.PlaybackOffset.URI)
+		}
+	}
+
+	if opts.PositionMs > 0 {
+		description += fmt.Sprintf(" at %d ms", opts.PositionMs)
+	}
+
+	return description
+}
@@ -0,0 +1,337 @@
+package playback
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/matching"
+	"spotify-mcp/internal/server/tools"
+)
+
+const (
+	playQuerySearchLimit       = 10
+	defaultPlayQueryAlternates = 3
+	// unknownPopularity stands in for albums and playlists, which search
+	// results don't give a popularity for.
+	unknownPopularity = 50
+	// playlistScoreFactor ranks playlists a little below tracks, albums and
+	// artists with the same name, since they are rarely what's meant.
+	playlistScoreFactor = 0.9
+)
+
+var playQueryTypes = map[string]spotify.SearchType{
+	"track":    spotify.SearchTypeTrack,
+	"album":    spotify.SearchTypeAlbum,
+	"playlist": spotify.SearchTypePlaylist,
+	"artist":   spotify.SearchTypeArtist,
+}
+
+// playCandidate is a search result scored against the query.
+type playCandidate struct {
+	itemType    string
+	uri         spotify.URI
+	description string
+	score       float64
+}
+
+func playQueryTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"play_query",
+		mcp.WithDescription("Find and play (or queue) the best match for a description such as \"Bohemian Rhapsody by Queen\", searching tracks, albums, playlists and artists at once. Returns the chosen item and alternates"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("What to play, e.g. \"Bohemian Rhapsody by Queen\", \"Abbey Road\" or \"lofi beats\""),
+		),
+		mcp.WithString("types",
+			mcp.Description("Comma-separated item types to consider: track, album, playlist, artist (default: all)"),
+		),
+		mcp.WithString("action",
+			mcp.Description("play to start the best match now, or queue to add it to the queue (default: play)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only return the ranked candidates without playing anything (default: false)"),
+		),
+		mcp.WithNumber("alternates",
+			mcp.Description("Number of alternates to return (default: 3)"),
+		),
+		withDeviceParameter(),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  playQueryBehaviour,
+	}
+}
+
+func playQueryBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := tools.GetParamFromRequest(request, "query")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query parameter: %w", err)
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return mcp.NewToolResultError("The query is empty."), nil
+	}
+
+	typesParam, _ := tools.GetParamFromRequest(request, "types")
+	searchType, err := playQuerySearchType(typesParam)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	action, _ := tools.GetParamFromRequest(request, "action")
+	action = strings.ToLower(strings.TrimSpace(action))
+	if action == "" {
+		action = "play"
+	}
+	if action != "play" && action != "queue" {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid action %q, use play or queue.", action)), nil
+	}
+
+	dryRun, _ := tools.GetBoolParamFromRequest(request, "dry_run")
+
+	alternates, err := tools.GetIntParamFromRequest(request, "alternates")
+	if err != nil || alternates < 0 {
+		alternates = defaultPlayQueryAlternates
+	}
+
+	if !dryRun && !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+	}
+
+	title, artist := matching.SplitTitleArtist(query)
+	searchQuery := strings.TrimSpace(title + " " + artist)
+
+	results, err := client.SpotifyClient.Search(ctx, searchQuery, searchType, spotify.Limit(playQuerySearchLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	candidates := rankPlayCandidates(results, query, title, artist)
+	if len(candidates) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Nothing matched %q.", query)), nil
+	}
+
+	if dryRun {
+		shown := candidates
+		if len(shown) > alternates+1 {
+			shown = shown[:alternates+1]
+		}
+
+		response := fmt.Sprintf("Candidates for %q:\n", query)
+		for i, candidate := range shown {
+			response += fmt.Sprintf("%d. %s\n", i+1, candidate)
+		}
+		return mcp.NewToolResultText(response), nil
+	}
+
+	best := candidates[0]
+
+	opts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var response string
+	var activated *activation
+	if action == "play" {
+		activated, err = startCandidate(ctx, best, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start playback: %w", err)
+		}
+		response = fmt.Sprintf("Playing %s%s", best, onDevice(device))
+	} else {
+		var summary string
+		summary, activated, err = queueCandidate(ctx, best, opts)
+		if err != nil {
+			return nil, err
+		}
+		response = fmt.Sprintf("Queued %s%s\n%s", best, onDevice(device), summary)
+	}
+
+	if len(candidates) > 1 && alternates > 0 {
+		others := candidates[1:]
+		if len(others) > alternates {
+			others = others[:alternates]
+		}
+
+		response += "\n\nAlternates:\n"
+		for i, candidate := range others {
+			response += fmt.Sprintf("%d. %s\n", i+1, candidate)
+		}
+	}
+
+	response += activated.String()
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func (c playCandidate) String() string {
+	return fmt.Sprintf("%s: %s (score %.2f)\n   URI: %s", c.itemType, c.description, c.score, c.uri)
+}
+
+func playQuerySearchType(typesParam string) (spotify.SearchType, error) {
+	values := tools.SplitCommaSeparated(typesParam)
+	if len(values) == 0 {
+		return spotify.SearchTypeTrack | spotify.SearchTypeAlbum | spotify.SearchTypePlaylist | spotify.SearchTypeArtist, nil
+	}
+
+	var searchType spotify.SearchType
+	for _, value := range values {
+		itemType, ok := playQueryTypes[strings.TrimSuffix(strings.ToLower(value), "s")]
+		if !ok {
+			return 0, fmt.Errorf("invalid type %q, use track, album, playlist or artist", value)
+		}
+		searchType |= itemType
+	}
+
+	return searchType, nil
+}
+
+// rankPlayCandidates scores every search result on how well its name and
+// artists match the query, with popularity as a tie breaker, best first.
+func rankPlayCandidates(results *spotify.SearchResult, query, title, artist string) []playCandidate {
+	var candidates []playCandidate
+
+	// score weighs the name and artist matches. Without an artist in the
+	// query the name is compared with the whole query, artists included.
+	score := func(name string, artists []string, popularity int) float64 {
+		popularityScore := float64(popularity) / 100
+		if artist != "" {
+			return 0.6*matching.TitleSimilarity(title, name) + 0.3*matching.BestSimilarity(artist, artists) + 0.1*popularityScore
+		}
+
+		nameScore := matching.TitleSimilarity(query, name)
+		if combined := matching.Similarity(query, name+" "+strings.Join(artists, " ")); combined > nameScore {
+			nameScore = combined
+		}
+		return 0.85*nameScore + 0.15*popularityScore
+	}
+
+	if results.Tracks != nil {
+		for _, track := range results.Tracks.Tracks {
+			artists := artistNames(track.Artists)
+			candidates = append(candidates, playCandidate{
+				itemType:    "track",
+				uri:         track.URI,
+				description: fmt.Sprintf("%s by %s (%s)", track.Name, strings.Join(artists, ", "), track.Album.Name),
+				score:       score(track.Name, artists, int(track.Popularity)),
+			})
+		}
+	}
+
+	if results.Albums != nil {
+		for _, album := range results.Albums.Albums {
+			artists := artistNames(album.Artists)
+			candidates = append(candidates, playCandidate{
+				itemType:    "album",
+				uri:         album.URI,
+				description: fmt.Sprintf("%s by %s", album.Name, strings.Join(artists, ", ")),
+				score:       score(album.Name, artists, unknownPopularity),
+			})
+		}
+	}
+
+	if results.Playlists != nil {
+		for _, playlist := range results.Playlists.Playlists {
+			if playlist.ID == "" {
+				continue
+			}
+			owner := []string{playlist.Owner.DisplayName}
+			candidates = append(candidates, playCandidate{
+				itemType:    "playlist",
+				uri:         playlist.URI,
+				description: fmt.Sprintf("%s by %s", playlist.Name, playlist.Owner.DisplayName),
+				score:       playlistScoreFactor * score(playlist.Name, owner, unknownPopularity),
+			})
+		}
+	}
+
+	if results.Artists != nil {
+		for _, resultArtist := range results.Artists.Artists {
+			want := query
+			if artist != "" {
+				want = artist
+			}
+			// An artist only matches the whole query, or the artist part
+			// when a title was given too, which points at a track instead.
+			artistScore := 0.85*matching.Similarity(want, resultArtist.Name) + 0.15*float64(resultArtist.Popularity)/100
+			if artist != "" {
+				artistScore *= 0.5
+			}
+			candidates = append(candidates, playCandidate{
+				itemType:    "artist",
+				uri:         resultArtist.URI,
+				description: resultArtist.Name,
+				score:       artistScore,
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	return candidates
+}
+
+func artistNames(artists []spotify.SimpleArtist) []string {
+	names := make([]string, len(artists))
+	for i, artist := range artists {
+		names[i] = artist.Name
+	}
+	return names
+}
+
+func startCandidate(ctx context.Context, candidate playCandidate, deviceOpts *spotify.PlayOptions) (*activation, error) {
+	opts := &spotify.PlayOptions{}
+	if deviceOpts != nil {
+		opts.DeviceID = deviceOpts.DeviceID
+	}
+
+	if candidate.itemType == "track" {
+		opts.URIs = []spotify.URI{candidate.uri}
+	} else {
+		opts.PlaybackContext = &candidate.uri
+	}
+
+	return runWithDeviceActivation(ctx, opts, func(opts *spotify.PlayOptions) error {
+		return client.AuthenticatedSpotifyClient.PlayOpt(ctx, opts)
+	})
+}
+
+// queueCandidate adds the candidate's tracks to the managed queue, the same
+// way add_tracks_to_queue does, and summarizes what was added.
+func queueCandidate(ctx context.Context, candidate playCandidate, opts *spotify.PlayOptions) (string, *activation, error) {
+	queued := queuedTrackIDs(ctx)
+
+	items, failures, err := expandQueueItems(ctx, []string{string(candidate.uri)}, queued, queueExpandOptions{})
+	if err != nil {
+		return "", nil, err
+	}
+
+	added, skipped := filterQueueItems(items, queued, queueExpandOptions{})
+	addToManagedQueue(added, false)
+
+	feedFailures, activated, feedErr := feedManagedQueue(ctx, opts)
+	failures = append(failures, feedFailures...)
+
+	summary := fmt.Sprintf("Added %d track(s) to the queue.", len(added))
+	if skipped > 0 {
+		summary += fmt.Sprintf(" Skipped %d track(s) already in the queue.", skipped)
+	}
+	for _, failure := range failures {
+		summary += fmt.Sprintf("\nFailed to queue %s", failure)
+	}
+	if feedErr != nil {
+		summary += fmt.Sprintf("\nThe tracks could not be sent to Spotify yet (%v). They stay in the managed queue.", feedErr)
+	}
+
+	return summary, activated, nil
+}
@@ -0,0 +1,113 @@
+package playback
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+)
+
+// playingItemTypes asks Spotify to report podcast episodes as well as tracks.
+var playingItemTypes = spotify.AdditionalTypes(spotify.EpisodeAdditionalType, spotify.TrackAdditionalType)
+
+func playbackStateTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"playback_state",
+		mcp.WithDescription("Get the full playback state: playing or paused, the current item, device, volume, shuffle, repeat and the context being played"),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  playbackStateBehaviour,
+	}
+}
+
+func playbackStateBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+	}
+
+	state, err := client.AuthenticatedSpotifyClient.PlayerState(ctx, playingItemTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playback state: %w", err)
+	}
+
+	return mcp.NewToolResultText(formatPlayerState(state)), nil
+}
+
+func formatPlayerState(state *spotify.PlayerState) string {
+	if state == nil || (state.Device.ID == "" && state.Item == nil) {
+		return "No active playback. Use list_devices to see the available devices."
+	}
+
+	rememberDevice(state.Device.ID)
+
+	status := "Paused"
+	if state.Playing {
+		status = "Playing"
+	}
+
+	response := fmt.Sprintf("Status: %s\n", status)
+	if state.Item != nil {
+		response += formatPlayingItem(state.Item, state.Progress)
+	} else {
+		response += "Item: none\n"
+	}
+
+	if state.PlaybackContext.URI != "" {
+		response += fmt.Sprintf("Context: %s (%s)\n", state.PlaybackContext.Type, state.PlaybackContext.URI)
+	}
+
+	response += fmt.Sprintf("Device: %s (%s)\n", state.Device.Name, state.Device.Type)
+	response += fmt.Sprintf("Device ID: %s\n", state.Device.ID)
+	response += fmt.Sprintf("Volume: %d%%\n", state.Device.Volume)
+	response += fmt.Sprintf("Shuffle: %t\n", state.ShuffleState)
+	response += fmt.Sprintf("Repeat: %s\n", state.RepeatState)
+
+	return response
+}
+
+// formatPlayingItem describes the current track or podcast episode along with
+// its progress.
+func formatPlayingItem(item *spotify.FullTrack, progress spotify.Numeric) string {
+	if item.Type == "episode" {
+		response := fmt.Sprintf("Episode: %s\n", item.Name)
+		response += fmt.Sprintf("Progress: %s / %s\n", formatDuration(int(progress)), formatDuration(int(item.Duration)))
+		response += fmt.Sprintf("URI: %s\n", item.URI)
+		return response
+	}
+
+	var artists []string
+	for _, artist := range item.Artists {
+		artists = append(artists, artist.Name)
+	}
+
+	response := fmt.Sprintf("Track: %s by %s\n", item.Name, strings.Join(artists, ", "))
+	response += fmt.Sprintf("Album: %s\n", item.Album.Name)
+	response += fmt.Sprintf("Progress: %s / %s\n", formatDuration(int(progress)), formatDuration(int(item.Duration)))
+	response += fmt.Sprintf("Track ID: %s\n", item.ID)
+
+	return response
+}
+
+// formatDuration renders milliseconds as m:ss, or h:mm:ss for an hour or more.
+func formatDuration(ms int) string {
+	if ms < 0 {
+		ms = 0
+	}
+
+	totalSeconds := ms / 1000
+	hours := totalSeconds / 3600
+	minutes := (totalSeconds % 3600) / 60
+	seconds := totalSeconds % 60
+
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
 	"spotify-mcp/internal/client"
 	"spotify-mcp/internal/server/tools"
 	"sync"
+	"time"
 )
 
 var (
@@ -23,6 +25,13 @@ func PlayerTools() []tools.ToolEntry {
 		previousTrackTool(),
 		shuffleTool(),
 		currentTrackTool(),
+		playbackStateTool(),
+		setVolumeTool(),
+		seekTool(),
+		setRepeatTool(),
+		fadeVolumeTool(),
+		playQueryTool(),
+		recentlyPlayedTool(),
 	}
 }
 
@@ -66,7 +75,7 @@ func loginBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.Call
 func currentTrackTool() tools.ToolEntry {
 	toolDefinition := mcp.NewTool(
 		"current_track",
-		mcp.WithDescription("Get information about the currently playing track"),
+		mcp.WithDescription("Get information about the current track or podcast episode, including when playback is paused"),
 	)
 
 	return tools.ToolEntry{
@@ -77,36 +86,25 @@ func currentTrackTool() tools.ToolEntry {
 
 func currentTrackBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
 	}
 
-	currentlyPlaying, err := client.AuthenticatedSpotifyClient.PlayerCurrentlyPlaying(ctx)
+	currentlyPlaying, err := client.AuthenticatedSpotifyClient.PlayerCurrentlyPlaying(ctx, playingItemTypes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get currently playing track: %w", err)
 	}
 
-	if currentlyPlaying == nil || !currentlyPlaying.Playing {
-		return mcp.NewToolResultText("No track is currently playing."), nil
+	if currentlyPlaying == nil || currentlyPlaying.Item == nil {
+		return mcp.NewToolResultText("Nothing is currently playing."), nil
 	}
 
-	track := currentlyPlaying.Item
-	artists := ""
-	for i, artist := range track.Artists {
-		if i > 0 {
-			artists += ", "
-		}
-		artists += artist.Name
+	status := "Paused"
+	if currentlyPlaying.Playing {
+		status = "Playing"
 	}
 
-	response := fmt.Sprintf(
-		"Currently playing: %s by %s\nAlbum: %s\nProgress: %d/%d ms\nIs Playing: %t",
-		track.Name,
-		artists,
-		track.Album.Name,
-		currentlyPlaying.Progress,
-		track.Duration,
-		currentlyPlaying.Playing,
-	)
+	response := fmt.Sprintf("Status: %s\n", status)
+	response += formatPlayingItem(currentlyPlaying.Item, currentlyPlaying.Progress)
 
 	return mcp.NewToolResultText(response), nil
 }
@@ -114,7 +112,20 @@ func currentTrackBehaviour(ctx context.Context, request mcp.CallToolRequest) (*m
 func playTool() tools.ToolEntry {
 	toolDefinition := mcp.NewTool(
 		"play",
-		mcp.WithDescription("Start or resume playback on your Spotify account"),
+		mcp.WithDescription("Start or resume playback on your Spotify account. Without arguments the current playback is resumed"),
+		mcp.WithString(contextURIParameter,
+			mcp.Description("Album, playlist, artist or show to play, as a Spotify URI or URL. Can not be combined with uris"),
+		),
+		mcp.WithString(urisParameter,
+			mcp.Description("Comma-separated list of track IDs, URIs or URLs to play. Can not be combined with context_uri"),
+		),
+		mcp.WithString(offsetParameter,
+			mcp.Description("Where to start in the context or uris: a zero-based index or a track URI. Not supported for artist contexts"),
+		),
+		mcp.WithNumber(positionMsParameter,
+			mcp.Description("Position in the first track to start from, in milliseconds"),
+		),
+		withDeviceParameter(),
 	)
 
 	return tools.ToolEntry{
@@ -125,15 +136,35 @@ func playTool() tools.ToolEntry {
 
 func playBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
 	}
 
-	err := client.AuthenticatedSpotifyClient.Play(ctx)
+	playOpts, err := playOptionsFromRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	deviceOpts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	opts := playOpts
+	if deviceOpts != nil {
+		if opts == nil {
+			opts = &spotify.PlayOptions{}
+		}
+		opts.DeviceID = deviceOpts.DeviceID
+	}
+
+	activated, err := runWithDeviceActivation(ctx, opts, func(opts *spotify.PlayOptions) error {
+		return client.AuthenticatedSpotifyClient.PlayOpt(ctx, opts)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to start playback: %w", err)
 	}
 
-	return mcp.NewToolResultText("Playback started"), nil
+	return mcp.NewToolResultText(describePlayOptions(playOpts) + onDevice(device) + activated.String()), nil
 }
 
 // Pause tool
@@ -141,6 +172,13 @@ func pauseTool() tools.ToolEntry {
 	toolDefinition := mcp.NewTool(
 		"pause",
 		mcp.WithDescription("Pause playback on your Spotify account"),
+		mcp.WithNumber("fade_seconds",
+			mcp.Description("Fade the volume out over this many seconds before pausing. The fade runs in the background (default: pause immediately)"),
+		),
+		mcp.WithBoolean("restore_volume",
+			mcp.Description("When fading, restore the original volume after pausing so the next playback is not silent (default: true)"),
+		),
+		withDeviceParameter(),
 	)
 
 	return tools.ToolEntry{
@@ -151,21 +189,54 @@ func pauseTool() tools.ToolEntry {
 
 func pauseBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
 	}
 
-	err := client.AuthenticatedSpotifyClient.Pause(ctx)
+	opts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	fadeSeconds, _ := tools.GetIntParamFromRequest(request, "fade_seconds")
+	if fadeSeconds > 0 {
+		restoreVolume, err := tools.GetBoolParamFromRequest(request, "restore_volume")
+		if err != nil {
+			restoreVolume = true
+		}
+
+		from, err := currentVolume(ctx, device)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		startFade(fadePlan{
+			opts:          opts,
+			from:          from,
+			to:            0,
+			duration:      time.Duration(fadeSeconds) * time.Second,
+			pause:         true,
+			restoreVolume: restoreVolume,
+		})
+
+		return mcp.NewToolResultText(fmt.Sprintf("Fading out over %d seconds, then pausing", fadeSeconds) + onDevice(device)), nil
+	}
+
+	cancelFade()
+
+	err = client.AuthenticatedSpotifyClient.PauseOpt(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to pause playback: %w", err)
 	}
+	rememberTarget(opts)
 
-	return mcp.NewToolResultText("Playback paused"), nil
+	return mcp.NewToolResultText("Playback paused" + onDevice(device)), nil
 }
 
 func nextTrackTool() tools.ToolEntry {
 	toolDefinition := mcp.NewTool(
 		"next_track",
 		mcp.WithDescription("Skip to the next track in your Spotify queue"),
+		withDeviceParameter(),
 	)
 
 	return tools.ToolEntry{
@@ -176,21 +247,29 @@ func nextTrackTool() tools.ToolEntry {
 
 func nextTrackBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
 	}
 
-	err := client.AuthenticatedSpotifyClient.Next(ctx)
+	opts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	activated, err := runWithDeviceActivation(ctx, opts, func(opts *spotify.PlayOptions) error {
+		return client.AuthenticatedSpotifyClient.NextOpt(ctx, opts)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to skip to next track: %w", err)
 	}
 
-	return mcp.NewToolResultText("Skipped to next track"), nil
+	return mcp.NewToolResultText("Skipped to next track" + onDevice(device) + activated.String()), nil
 }
 
 func previousTrackTool() tools.ToolEntry {
 	toolDefinition := mcp.NewTool(
 		"previous_track",
 		mcp.WithDescription("Skip to the previous track in your Spotify queue"),
+		withDeviceParameter(),
 	)
 
 	return tools.ToolEntry{
@@ -201,15 +280,22 @@ func previousTrackTool() tools.ToolEntry {
 
 func previousTrackBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
 	}
 
-	err := client.AuthenticatedSpotifyClient.Previous(ctx)
+	opts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	activated, err := runWithDeviceActivation(ctx, opts, func(opts *spotify.PlayOptions) error {
+		return client.AuthenticatedSpotifyClient.PreviousOpt(ctx, opts)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to skip to previous track: %w", err)
 	}
 
-	return mcp.NewToolResultText("Skipped to previous track"), nil
+	return mcp.NewToolResultText("Skipped to previous track" + onDevice(device) + activated.String()), nil
 }
 
 func shuffleTool() tools.ToolEntry {
@@ -220,6 +306,7 @@ func shuffleTool() tools.ToolEntry {
 			mcp.Description("Set to true to enable shuffle, false to disable"),
 			mcp.Required(),
 		),
+		withDeviceParameter(),
 	)
 
 	return tools.ToolEntry{
@@ -230,7 +317,7 @@ func shuffleTool() tools.ToolEntry {
 
 func shuffleBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
 	}
 
 	shuffleState, err := tools.GetBoolParamFromRequest(request, "state")
@@ -238,7 +325,14 @@ func shuffleBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 		return nil, fmt.Errorf("failed to get shuffle state parameter: %w", err)
 	}
 
-	err = client.AuthenticatedSpotifyClient.Shuffle(ctx, shuffleState)
+	opts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	activated, err := runWithDeviceActivation(ctx, opts, func(opts *spotify.PlayOptions) error {
+		return client.AuthenticatedSpotifyClient.ShuffleOpt(ctx, shuffleState, opts)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to set shuffle state: %w", err)
 	}
@@ -248,5 +342,5 @@ func shuffleBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 		statusMsg = "Shuffle enabled"
 	}
 
-	return mcp.NewToolResultText(statusMsg), nil
+	return mcp.NewToolResultText(statusMsg + onDevice(device) + activated.String()), nil
 }
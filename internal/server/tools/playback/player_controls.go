@@ -0,0 +1,331 @@
+package playback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+)
+
+var (
+	errNothingToSeek = errors.New("nothing is playing")
+	errOtherDevice   = errors.New("the device isn't the one playing")
+)
+
+var repeatStates = map[string]bool{
+	"off":     true,
+	"track":   true,
+	"context": true,
+}
+
+func setVolumeTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"set_volume",
+		mcp.WithDescription("Set the playback volume, either to an absolute level or by a relative step, and show the resulting playback state"),
+		mcp.WithNumber("level",
+			mcp.Description("Volume level from 0 to 100"),
+		),
+		mcp.WithNumber("step",
+			mcp.Description("Amount to change the current volume by, e.g. 10 or -10. Ignored when level is set"),
+		),
+		withDeviceParameter(),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  setVolumeBehaviour,
+	}
+}
+
+func setVolumeBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+	}
+
+	level, levelErr := tools.GetIntParamFromRequest(request, "level")
+	step, stepErr := tools.GetIntParamFromRequest(request, "step")
+	if levelErr != nil && stepErr != nil {
+		return mcp.NewToolResultError("Provide either level (0-100) or step (e.g. 10 or -10)."), nil
+	}
+
+	opts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	previous := -1
+	if levelErr != nil {
+		previous, err = currentVolume(ctx, device)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		level = previous + step
+	}
+
+	level = clamp(level, 0, 100)
+
+	fadeCancelled := cancelFade()
+
+	activated, err := runWithDeviceActivation(ctx, opts, func(opts *spotify.PlayOptions) error {
+		return client.AuthenticatedSpotifyClient.VolumeOpt(ctx, level, opts)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set volume: %w", err)
+	}
+
+	response := fmt.Sprintf("Volume set to %d%%", level)
+	if previous >= 0 {
+		response = fmt.Sprintf("Volume changed from %d%% to %d%%", previous, level)
+	}
+
+	response += onDevice(device)
+	if fadeCancelled {
+		response += ". The volume fade in progress was cancelled"
+	}
+	response += activated.String() + "\n\n"
+
+	state, err := client.AuthenticatedSpotifyClient.PlayerState(ctx, playingItemTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playback state: %w", err)
+	}
+	response += formatPlayerState(state)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// currentVolume returns the volume of device, or of the active device when
+// device is nil.
+func currentVolume(ctx context.Context, device *spotify.PlayerDevice) (int, error) {
+	if device != nil {
+		return int(device.Volume), nil
+	}
+
+	state, err := client.AuthenticatedSpotifyClient.PlayerState(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get playback state: %w", err)
+	}
+
+	if state == nil || state.Device.ID == "" {
+		return 0, errors.New("no device is active, so the current volume is unknown. Pass level instead of step, or choose a device")
+	}
+
+	return int(state.Device.Volume), nil
+}
+
+func seekTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"seek",
+		mcp.WithDescription("Seek to a position in the current track and show the resulting playback state"),
+		mcp.WithString("position",
+			mcp.Required(),
+			mcp.Description("Absolute position such as \"1:45\", \"90s\" or \"90\" (seconds), or a relative jump such as \"+30s\" or \"-10s\""),
+		),
+		withDeviceParameter(),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  seekBehaviour,
+	}
+}
+
+func seekBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+	}
+
+	positionValue, err := tools.GetParamFromRequest(request, "position")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get position parameter: %w", err)
+	}
+
+	positionMs, relative, err := parseSeekPosition(positionValue)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	opts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// The position is worked out inside the command, so that after a device
+	// is activated it comes from the activated device's playback.
+	var seekedTo, duration int
+	var itemName string
+	activated, err := runWithDeviceActivation(ctx, opts, func(opts *spotify.PlayOptions) error {
+		state, err := client.AuthenticatedSpotifyClient.PlayerState(ctx, playingItemTypes)
+		if err != nil {
+			return fmt.Errorf("failed to get playback state: %w", err)
+		}
+
+		if state == nil || state.Device.ID == "" {
+			if opts == nil || opts.DeviceID == nil {
+				return errNoActiveDevice
+			}
+			return errNothingToSeek
+		}
+		if opts != nil && opts.DeviceID != nil && state.Device.ID != *opts.DeviceID {
+			return fmt.Errorf("%w: playback is on %s, use transfer_playback to move it first", errOtherDevice, state.Device.Name)
+		}
+		if state.Item == nil {
+			return errNothingToSeek
+		}
+
+		seekedTo = positionMs
+		if relative {
+			seekedTo += int(state.Progress)
+		}
+		duration = int(state.Item.Duration)
+		seekedTo = clamp(seekedTo, 0, duration)
+		itemName = state.Item.Name
+
+		return client.AuthenticatedSpotifyClient.SeekOpt(ctx, seekedTo, opts)
+	})
+	if errors.Is(err, errNothingToSeek) {
+		return mcp.NewToolResultError("Nothing is currently playing" + onDevice(device) + ", so there is nothing to seek in."), nil
+	}
+	if errors.Is(err, errOtherDevice) {
+		return mcp.NewToolResultError(fmt.Sprintf("Can't seek%s: %v.", onDevice(device), err)), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to seek: %w", err)
+	}
+
+	response := fmt.Sprintf("Seeked to %s / %s in %s", formatDuration(seekedTo), formatDuration(duration), itemName)
+	response += onDevice(device) + activated.String() + "\n\n"
+
+	state, err := client.AuthenticatedSpotifyClient.PlayerState(ctx, playingItemTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playback state: %w", err)
+	}
+	response += formatPlayerState(state)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// parseSeekPosition parses a seek position into milliseconds. A leading + or -
+// makes the position relative to the current progress. Positions may be
+// timestamps ("1:45", "1:02:03"), Go durations ("90s", "1m30s", "500ms") or a
+// bare number of seconds.
+func parseSeekPosition(value string) (int, bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false, errors.New("position must not be empty")
+	}
+
+	relative := false
+	sign := 1
+	switch value[0] {
+	case '+':
+		relative = true
+		value = value[1:]
+	case '-':
+		relative = true
+		sign = -1
+		value = value[1:]
+	}
+
+	var ms int
+	switch {
+	case strings.Contains(value, ":"):
+		parts := strings.Split(value, ":")
+		if len(parts) > 3 {
+			return 0, false, fmt.Errorf("invalid timestamp %q, use m:ss or h:mm:ss", value)
+		}
+		seconds := 0
+		for _, part := range parts {
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 0 {
+				return 0, false, fmt.Errorf("invalid timestamp %q, use m:ss or h:mm:ss", value)
+			}
+			seconds = seconds*60 + n
+		}
+		ms = seconds * 1000
+	default:
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+			ms = int(seconds * 1000)
+			break
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid position %q, use a timestamp like 1:45, a duration like 30s, or +30s/-10s to jump", value)
+		}
+		ms = int(duration.Milliseconds())
+	}
+
+	return sign * ms, relative, nil
+}
+
+func setRepeatTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"set_repeat",
+		mcp.WithDescription("Set the repeat mode and show the resulting playback state"),
+		mcp.WithString("state",
+			mcp.Required(),
+			mcp.Description("Repeat mode: off, track (repeat the current track) or context (repeat the album or playlist)"),
+		),
+		withDeviceParameter(),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  setRepeatBehaviour,
+	}
+}
+
+func setRepeatBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+	}
+
+	repeatState, err := tools.GetParamFromRequest(request, "state")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repeat state parameter: %w", err)
+	}
+
+	repeatState = strings.ToLower(strings.TrimSpace(repeatState))
+	if !repeatStates[repeatState] {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid repeat state %q. Use off, track or context.", repeatState)), nil
+	}
+
+	opts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	activated, err := runWithDeviceActivation(ctx, opts, func(opts *spotify.PlayOptions) error {
+		return client.AuthenticatedSpotifyClient.RepeatOpt(ctx, repeatState, opts)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set repeat state: %w", err)
+	}
+
+	response := fmt.Sprintf("Repeat mode set to %s", repeatState)
+	response += onDevice(device) + activated.String() + "\n\n"
+
+	state, err := client.AuthenticatedSpotifyClient.PlayerState(ctx, playingItemTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playback state: %w", err)
+	}
+	response += formatPlayerState(state)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func clamp(value, minimum, maximum int) int {
+	if value < minimum {
+		return minimum
+	}
+	if value > maximum {
+		return maximum
+	}
+	return value
+}
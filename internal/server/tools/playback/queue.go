@@ -12,16 +12,18 @@ import (
 )
 
 func QueueTools() []tools.ToolEntry {
-	return []tools.ToolEntry{
+	queueTools := []tools.ToolEntry{
 		getQueueTool(),
 		queueSongTool(),
 	}
+
+	return append(queueTools, managedQueueTools()...)
 }
 
 func getQueueTool() tools.ToolEntry {
 	toolDefinition := mcp.NewTool(
 		"get_queue",
-		mcp.WithDescription("Get the current Spotify playback queue"),
+		mcp.WithDescription("Get the current Spotify playback queue, including the managed queue of tracks that can still be reordered or removed"),
 	)
 
 	return tools.ToolEntry{
@@ -32,7 +34,7 @@ func getQueueTool() tools.ToolEntry {
 
 func getQueueBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
 	}
 
 	queue, err := client.AuthenticatedSpotifyClient.GetQueue(ctx)
@@ -42,27 +44,64 @@ func getQueueBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 
 	currentlyPlaying := formatTrack(&queue.CurrentlyPlaying, "Currently Playing")
 
-	var queueItems []string
-	if len(queue.Items) == 0 {
-		queueItems = append(queueItems, "No upcoming tracks in the queue.")
-	} else {
-		for i, track := range queue.Items {
-			queueItems = append(queueItems, formatTrack(&track, fmt.Sprintf("Queue #%d", i+1)))
+	fed, pending := managedQueue()
+
+	// Tracks sent from the managed queue also show up in Spotify's queue;
+	// list them once, as part of the managed queue.
+	sent := map[spotify.ID]int{}
+	for _, item := range fed {
+		sent[item.id]++
+	}
+
+	var spotifyItems []string
+	for _, track := range queue.Items {
+		if sent[track.ID] > 0 {
+			sent[track.ID]--
+			continue
 		}
+		spotifyItems = append(spotifyItems, formatTrack(&track, fmt.Sprintf("Queue #%d", len(spotifyItems)+1)))
 	}
 
-	response := currentlyPlaying + "\n\nUpcoming in Queue:\n" + strings.Join(queueItems, "\n\n")
+	response := currentlyPlaying
+
+	if len(fed) > 0 || len(pending) > 0 {
+		var managedItems []string
+		for _, item := range fed {
+			managedItems = append(managedItems, formatQueueItem(item, "Sent to Spotify"))
+		}
+		for i, item := range pending {
+			managedItems = append(managedItems, formatQueueItem(item, fmt.Sprintf("Position %d", i+1)))
+		}
+
+		response += "\n\nManaged Queue:\n" + strings.Join(managedItems, "\n\n")
+		if len(fed) > 0 {
+			response += "\n\nTracks sent to Spotify can no longer be moved or removed."
+		}
+	}
+
+	if len(spotifyItems) == 0 {
+		spotifyItems = append(spotifyItems, "No upcoming tracks in the queue.")
+	}
+
+	response += "\n\nUpcoming in Spotify's Queue:\n" + strings.Join(spotifyItems, "\n\n")
 	return mcp.NewToolResultText(response), nil
 }
 
 func queueSongTool() tools.ToolEntry {
 	toolDefinition := mcp.NewTool(
 		"add_tracks_to_queue",
-		mcp.WithDescription("Add tracks to your Spotify queue"),
+		mcp.WithDescription("Add tracks, albums, playlists or an artist's top tracks to the end of the managed queue. Tracks already in the managed queue or playing now are skipped. The server hands them to Spotify shortly before they play, so they can still be reordered or removed until then"),
 		mcp.WithString("Track IDs",
-			mcp.Description("Comma-separated list of Spotify track IDs"),
+			mcp.Description("Comma-separated list of Spotify track, album, playlist or artist IDs, URIs or links. Bare IDs are treated as tracks"),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("shuffle",
+			mcp.Description("Shuffle the tracks before queueing them (default: false)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of tracks to queue (default: no limit)"),
+		),
+		withDeviceParameter(),
 	)
 
 	return tools.ToolEntry{
@@ -73,7 +112,7 @@ func queueSongTool() tools.ToolEntry {
 
 func queueSongBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
 	}
 
 	trackIdsParam, err := tools.GetParamFromRequest(request, "Track IDs")
@@ -81,34 +120,63 @@ func queueSongBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 		return nil, fmt.Errorf("failed to get track IDs parameter: %w", err)
 	}
 
-	trackIds := strings.Split(trackIdsParam, ",")
+	values := tools.SplitCommaSeparated(trackIdsParam)
+	if len(values) == 0 {
+		return mcp.NewToolResultError("No track IDs were given."), nil
+	}
 
-	for i := range trackIds {
-		trackIds[i] = strings.TrimSpace(trackIds[i])
+	var expandOptions queueExpandOptions
+	expandOptions.shuffle, _ = tools.GetBoolParamFromRequest(request, "shuffle")
+	expandOptions.limit, _ = tools.GetIntParamFromRequest(request, "limit")
+	if expandOptions.limit < 0 {
+		return mcp.NewToolResultError("limit must not be negative."), nil
 	}
 
-	var failedTracks []string
-	var addedTracks []string
+	opts, device, err := deviceOptionsFromRequest(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	for _, trackId := range trackIds {
-		err := client.AuthenticatedSpotifyClient.QueueSong(ctx, spotify.ID(trackId))
-		if err != nil {
-			failedTracks = append(failedTracks, trackId)
-		} else {
-			addedTracks = append(addedTracks, trackId)
-		}
+	queued := queuedTrackIDs(ctx)
+
+	items, failures, err := expandQueueItems(ctx, values, queued, expandOptions)
+	if err != nil {
+		return nil, err
 	}
 
+	addedTracks, skipped := filterQueueItems(items, queued, expandOptions)
+
+	addToManagedQueue(addedTracks, false)
+
+	feedFailures, activated, feedErr := feedManagedQueue(ctx, opts)
+	failures = append(failures, feedFailures...)
+
 	var responseMsg string
 	if len(addedTracks) > 0 {
-		responseMsg = fmt.Sprintf("Successfully added %d track(s) to your queue.", len(addedTracks))
+		responseMsg = fmt.Sprintf("Successfully added %d track(s) to your queue%s.", len(addedTracks), onDevice(device))
+	} else {
+		responseMsg = "No tracks were added to your queue."
+	}
+
+	if skipped > 0 {
+		responseMsg += fmt.Sprintf("\nSkipped %d track(s) already in the queue.", skipped)
 	}
 
-	if len(failedTracks) > 0 {
-		if responseMsg != "" {
-			responseMsg += "\n"
+	if len(failures) > 0 {
+		responseMsg += fmt.Sprintf("\nFailed to add %d item(s):", len(failures))
+		for _, failure := range failures {
+			responseMsg += fmt.Sprintf("\n- %s", failure)
 		}
-		responseMsg += fmt.Sprintf("Failed to add %d track(s): %s", len(failedTracks), strings.Join(failedTracks, ", "))
+	}
+
+	if feedErr != nil {
+		responseMsg += fmt.Sprintf("\nThe tracks could not be sent to Spotify yet (%v). They stay in the managed queue.", feedErr)
+	}
+
+	responseMsg += activated.String()
+
+	if len(addedTracks) == 0 && len(failures) > 0 {
+		return mcp.NewToolResultError(responseMsg), nil
 	}
 
 	return mcp.NewToolResultText(responseMsg), nil
@@ -132,3 +200,12 @@ func formatTrack(track *spotify.FullTrack, prefix string) string {
 		track.Duration,
 	)
 }
+
+func formatQueueItem(item queueItem, prefix string) string {
+	return fmt.Sprintf("%s: %s\nAlbum: %s\nDuration: %s",
+		prefix,
+		item,
+		item.album,
+		formatDuration(item.duration),
+	)
+}
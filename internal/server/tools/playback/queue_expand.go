@@ -0,0 +1,345 @@
+package playback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+)
+
+// topTracksMarket asks Spotify for the top tracks in the signed-in user's
+// country.
+const topTracksMarket = "from_token"
+
+// queueFailure explains why something could not be queued.
+type queueFailure struct {
+	item   string
+	reason string
+}
+
+func (f queueFailure) String() string {
+	return fmt.Sprintf("%s: %s", f.item, f.reason)
+}
+
+// queueExpandOptions controls how queued items are expanded.
+type queueExpandOptions struct {
+	shuffle bool
+	// limit caps the number of tracks queued, 0 for no limit.
+	limit int
+}
+
+// queueCounter counts the expanded tracks that are not queued yet, so that
+// expanding can stop once there are enough of them for the limit.
+type queueCounter struct {
+	seen  map[spotify.ID]bool
+	fresh int
+	// limit is the number of new tracks wanted, 0 for all of them.
+	limit int
+}
+
+func newQueueCounter(queued map[spotify.ID]bool, opts queueExpandOptions) *queueCounter {
+	counter := &queueCounter{seen: map[spotify.ID]bool{}}
+	for id := range queued {
+		counter.seen[id] = true
+	}
+	// Shuffling picks from all the tracks, so every one is needed.
+	if !opts.shuffle {
+		counter.limit = opts.limit
+	}
+	return counter
+}
+
+func (c *queueCounter) add(items []queueItem) {
+	for _, item := range items {
+		if !c.seen[item.id] {
+			c.seen[item.id] = true
+			c.fresh++
+		}
+	}
+}
+
+// full reports whether enough new tracks have been found.
+func (c *queueCounter) full() bool {
+	return c.limit > 0 && c.fresh >= c.limit
+}
+
+// fetchPagesUntil fetches pages in order from offset until page reports that
+// it has enough items or there are none left.
+func fetchPagesUntil[T any](ctx context.Context, fetch client.PageFetcher[T], offset, pageSize int, page func([]T) bool) error {
+	for {
+		items, total, err := fetch(ctx, offset, pageSize)
+		if err != nil {
+			return err
+		}
+		offset += len(items)
+		if page(items) || len(items) == 0 || offset >= total {
+			return nil
+		}
+	}
+}
+
+// spotifyErrorReason returns Spotify's message for err, or the error itself.
+func spotifyErrorReason(err error) string {
+	var spotifyErr spotify.Error
+	if errors.As(err, &spotifyErr) && spotifyErr.Message != "" {
+		return spotifyErr.Message
+	}
+	return err.Error()
+}
+
+// isItemError reports whether err is about the item being queued, such as a
+// track that is unavailable, rather than the player or the connection.
+func isItemError(err error) bool {
+	var spotifyErr spotify.Error
+	if !errors.As(err, &spotifyErr) || isNoActiveDeviceError(err) {
+		return false
+	}
+
+	switch spotifyErr.Status {
+	case http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound:
+		return true
+	default:
+		return false
+	}
+}
+
+// expandQueueItems resolves tracks, albums, playlists and artists to the
+// tracks to queue, in the order given. Without shuffling, albums and playlists
+// are only read until they give limit tracks that are not in queued.
+func expandQueueItems(ctx context.Context, values []string, queued map[spotify.ID]bool, opts queueExpandOptions) ([]queueItem, []queueFailure, error) {
+	type source struct {
+		value    string
+		itemType string
+		id       spotify.ID
+	}
+
+	var sources []source
+	var failures []queueFailure
+	var trackIDs []spotify.ID
+
+	for _, value := range values {
+		itemType, id, err := tools.ParseSpotifyURI(value, "track")
+		if err != nil {
+			failures = append(failures, queueFailure{item: value, reason: err.Error()})
+			continue
+		}
+
+		switch itemType {
+		case "track":
+			trackIDs = append(trackIDs, id)
+		case "album", "playlist", "artist":
+		default:
+			failures = append(failures, queueFailure{item: value, reason: "only tracks, albums, playlists and artists can be queued"})
+			continue
+		}
+
+		sources = append(sources, source{value: value, itemType: itemType, id: id})
+	}
+
+	tracks := map[spotify.ID]queueItem{}
+	if len(trackIDs) > 0 {
+		found, missing, err := lookupQueueItems(ctx, trackIDs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to look up tracks: %w", err)
+		}
+		for _, item := range found {
+			tracks[item.id] = item
+		}
+		for _, id := range missing {
+			failures = append(failures, queueFailure{item: string(id), reason: "track not found"})
+		}
+	}
+
+	counter := newQueueCounter(queued, opts)
+
+	var items []queueItem
+	for _, src := range sources {
+		if counter.full() {
+			break
+		}
+
+		var expanded []queueItem
+		var err error
+
+		switch src.itemType {
+		case "track":
+			if item, ok := tracks[src.id]; ok {
+				expanded = []queueItem{item}
+			}
+		case "album":
+			expanded, err = albumQueueItems(ctx, src.id, counter)
+		case "playlist":
+			var skipped int
+			expanded, skipped, err = playlistQueueItems(ctx, src.id, counter)
+			if skipped > 0 {
+				failures = append(failures, queueFailure{item: src.value, reason: fmt.Sprintf("skipped %d episode(s) or local file(s)", skipped)})
+			}
+		case "artist":
+			expanded, err = artistQueueItems(ctx, src.id)
+		}
+
+		if err != nil {
+			failures = append(failures, queueFailure{item: src.value, reason: spotifyErrorReason(err)})
+			continue
+		}
+
+		if src.itemType != "album" && src.itemType != "playlist" {
+			counter.add(expanded)
+		}
+		items = append(items, expanded...)
+	}
+
+	return items, failures, nil
+}
+
+// albumQueueItems returns the tracks of an album, adding them to counter.
+func albumQueueItems(ctx context.Context, albumID spotify.ID, counter *queueCounter) ([]queueItem, error) {
+	album, err := client.AuthenticatedSpotifyClient.GetAlbum(ctx, albumID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []queueItem
+	addTracks := func(tracks []spotify.SimpleTrack) bool {
+		page := make([]queueItem, 0, len(tracks))
+		for _, track := range tracks {
+			item := newQueueItem(&spotify.FullTrack{SimpleTrack: track})
+			item.album = album.Name
+			page = append(page, item)
+		}
+		counter.add(page)
+		items = append(items, page...)
+		return counter.full()
+	}
+
+	if addTracks(album.Tracks.Tracks) || int(album.Tracks.Total) <= len(album.Tracks.Tracks) {
+		return items, nil
+	}
+
+	fetcher := client.AlbumTracksFetcher(client.AuthenticatedSpotifyClient, albumID)
+	if counter.limit == 0 {
+		rest, err := client.FetchAll(ctx, fetcher, client.FetchAllOptions{})
+		if err != nil {
+			return nil, err
+		}
+		addTracks(rest[min(len(album.Tracks.Tracks), len(rest)):])
+		return items, nil
+	}
+
+	if err := fetchPagesUntil(ctx, fetcher, len(album.Tracks.Tracks), 50, addTracks); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// playlistQueueItems returns the tracks of a playlist, adding them to
+// counter, and how many episodes and local files were left out.
+func playlistQueueItems(ctx context.Context, playlistID spotify.ID, counter *queueCounter) ([]queueItem, int, error) {
+	var items []queueItem
+	skipped := 0
+	addItems := func(playlistItems []spotify.PlaylistItem) bool {
+		var page []queueItem
+		for _, playlistItem := range playlistItems {
+			track := playlistItem.Track.Track
+			if track == nil || playlistItem.IsLocal || track.ID == "" {
+				skipped++
+				continue
+			}
+			page = append(page, newQueueItem(track))
+		}
+		counter.add(page)
+		items = append(items, page...)
+		return counter.full()
+	}
+
+	fetcher := client.PlaylistItemsFetcher(client.AuthenticatedSpotifyClient, playlistID)
+	if counter.limit == 0 {
+		playlistItems, err := client.FetchAll(ctx, fetcher, client.FetchAllOptions{})
+		if err != nil {
+			return nil, 0, err
+		}
+		addItems(playlistItems)
+		return items, skipped, nil
+	}
+
+	if err := fetchPagesUntil(ctx, fetcher, 0, 100, addItems); err != nil {
+		return nil, 0, err
+	}
+
+	return items, skipped, nil
+}
+
+func artistQueueItems(ctx context.Context, artistID spotify.ID) ([]queueItem, error) {
+	topTracks, err := client.AuthenticatedSpotifyClient.GetArtistsTopTracks(ctx, artistID, topTracksMarket)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]queueItem, len(topTracks))
+	for i := range topTracks {
+		items[i] = newQueueItem(&topTracks[i])
+	}
+
+	return items, nil
+}
+
+// queuedTrackIDs returns the tracks in the managed queue and the one
+// playing now. The rest of Spotify's queue isn't counted: it also lists the
+// upcoming tracks of the playing album or playlist, and doesn't mark which
+// items were queued, so queueing the current album would skip every track
+// left in it.
+func queuedTrackIDs(ctx context.Context) map[spotify.ID]bool {
+	queued := map[spotify.ID]bool{}
+
+	fed, pending := managedQueue()
+	for _, item := range append(fed, pending...) {
+		queued[item.id] = true
+	}
+
+	playing, err := client.AuthenticatedSpotifyClient.PlayerCurrentlyPlaying(ctx)
+	if err != nil {
+		log.Printf("Failed to get the currently playing track: %v", err)
+		return queued
+	}
+
+	if playing != nil && playing.Item != nil {
+		queued[playing.Item.ID] = true
+	}
+
+	return queued
+}
+
+// filterQueueItems drops tracks that are already queued or repeated, then
+// applies the shuffle and limit options. It returns the tracks to queue and
+// how many were skipped as duplicates.
+func filterQueueItems(items []queueItem, queued map[spotify.ID]bool, opts queueExpandOptions) ([]queueItem, int) {
+	var kept []queueItem
+	skipped := 0
+	for _, item := range items {
+		if queued[item.id] {
+			skipped++
+			continue
+		}
+		queued[item.id] = true
+		kept = append(kept, item)
+	}
+
+	if opts.shuffle {
+		rand.Shuffle(len(kept), func(i, j int) {
+			kept[i], kept[j] = kept[j], kept[i]
+		})
+	}
+
+	if opts.limit > 0 && len(kept) > opts.limit {
+		kept = kept[:opts.limit]
+	}
+
+	return kept, skipped
+}
@@ -0,0 +1,213 @@
+package playback
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+	"spotify-mcp/internal/timeparse"
+)
+
+const (
+	defaultRecentlyPlayedLimit = 20
+	// maxRecentlyPlayedLimit is the most plays Spotify returns per request.
+	maxRecentlyPlayedLimit = 50
+)
+
+func recentlyPlayedTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"recently_played",
+		mcp.WithDescription("List recently played tracks, newest first, grouped by the album, playlist or artist they were played from"),
+		mcp.WithString("after",
+			mcp.Description("Only plays after this time, e.g. \"1h ago\", \"yesterday\", \"14:30\" or \"2006-01-02 15:04\""),
+		),
+		mcp.WithString("before",
+			mcp.Description("Only plays before this time, in the same forms as after"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of plays to return, up to 50 (default: 20)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  recentlyPlayedBehaviour,
+	}
+}
+
+func recentlyPlayedBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+	}
+
+	now := time.Now()
+
+	var after, before time.Time
+	if value, _ := tools.GetParamFromRequest(request, "after"); strings.TrimSpace(value) != "" {
+		parsed, err := timeparse.ParsePastTime(value, now)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid after: %v", err)), nil
+		}
+		after = parsed
+	}
+	if value, _ := tools.GetParamFromRequest(request, "before"); strings.TrimSpace(value) != "" {
+		parsed, err := timeparse.ParsePastTime(value, now)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid before: %v", err)), nil
+		}
+		before = parsed
+	}
+
+	if !after.IsZero() && !before.IsZero() && !after.Before(before) {
+		return mcp.NewToolResultError("after must be earlier than before."), nil
+	}
+
+	limit, err := tools.GetIntParamFromRequest(request, "limit")
+	if err != nil || limit <= 0 {
+		limit = defaultRecentlyPlayedLimit
+	}
+	if limit > maxRecentlyPlayedLimit {
+		limit = maxRecentlyPlayedLimit
+	}
+
+	// Spotify accepts only one of before and after. With both, ask for the
+	// plays before and drop those too early.
+	opts := &spotify.RecentlyPlayedOptions{Limit: spotify.Numeric(maxRecentlyPlayedLimit)}
+	switch {
+	case !before.IsZero():
+		opts.BeforeEpochMs = before.UnixMilli()
+	case !after.IsZero():
+		opts.AfterEpochMs = after.UnixMilli()
+	default:
+		opts.Limit = spotify.Numeric(limit)
+	}
+
+	items, err := client.AuthenticatedSpotifyClient.PlayerRecentlyPlayedOpt(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recently played tracks: %w", err)
+	}
+
+	var plays []spotify.RecentlyPlayedItem
+	for _, item := range items {
+		if !after.IsZero() && !item.PlayedAt.After(after) {
+			continue
+		}
+		plays = append(plays, item)
+	}
+
+	// With only after set Spotify returns the oldest plays in range first,
+	// so keep the newest.
+	if len(plays) > limit {
+		if !after.IsZero() && before.IsZero() {
+			plays = plays[len(plays)-limit:]
+		} else {
+			plays = plays[:limit]
+		}
+	}
+
+	if len(plays) == 0 {
+		return mcp.NewToolResultText("No plays found in that time range."), nil
+	}
+
+	return mcp.NewToolResultText(formatRecentlyPlayed(ctx, plays, now)), nil
+}
+
+// formatRecentlyPlayed lists plays newest first, starting a new group each
+// time the context they were played from changes.
+func formatRecentlyPlayed(ctx context.Context, plays []spotify.RecentlyPlayedItem, now time.Time) string {
+	// Spotify's order is newest first, except for after-only queries.
+	if len(plays) > 1 && plays[0].PlayedAt.Before(plays[len(plays)-1].PlayedAt) {
+		for i, j := 0, len(plays)-1; i < j; i, j = i+1, j-1 {
+			plays[i], plays[j] = plays[j], plays[i]
+		}
+	}
+
+	names := map[spotify.URI]string{}
+	response := fmt.Sprintf("Recently played (%d track(s)):\n", len(plays))
+
+	var currentContext spotify.URI
+	for i, play := range plays {
+		if i == 0 || play.PlaybackContext.URI != currentContext {
+			currentContext = play.PlaybackContext.URI
+			response += "\n" + describePlayContext(ctx, play, names) + ":\n"
+		}
+
+		response += fmt.Sprintf("- %s (%s) %s by %s [%s]\n",
+			play.PlayedAt.Local().Format("2006-01-02 15:04"),
+			formatAgo(now.Sub(play.PlayedAt)),
+			play.Track.Name,
+			strings.Join(artistNames(play.Track.Artists), ", "),
+			play.Track.ID,
+		)
+	}
+
+	return response
+}
+
+// describePlayContext names the context of a play, looking up playlist and
+// artist names once each.
+func describePlayContext(ctx context.Context, play spotify.RecentlyPlayedItem, names map[spotify.URI]string) string {
+	playContext := play.PlaybackContext
+	if playContext.URI == "" {
+		return "Not from an album or playlist"
+	}
+
+	name, ok := names[playContext.URI]
+	if !ok {
+		name = lookupContextName(ctx, playContext)
+		names[playContext.URI] = name
+	}
+
+	if name == "" {
+		return fmt.Sprintf("From %s %s", playContext.Type, playContext.URI)
+	}
+
+	return fmt.Sprintf("From %s %q (%s)", playContext.Type, name, playContext.URI)
+}
+
+func lookupContextName(ctx context.Context, playContext spotify.PlaybackContext) string {
+	_, id, err := tools.ParseSpotifyURI(string(playContext.URI), "")
+	if err != nil {
+		return ""
+	}
+
+	switch playContext.Type {
+	case "playlist":
+		playlist, err := client.AuthenticatedSpotifyClient.GetPlaylist(ctx, id, spotify.Fields("name"))
+		if err == nil {
+			return playlist.Name
+		}
+	case "album":
+		album, err := client.AuthenticatedSpotifyClient.GetAlbum(ctx, id)
+		if err == nil {
+			return album.Name
+		}
+	case "artist":
+		artist, err := client.AuthenticatedSpotifyClient.GetArtist(ctx, id)
+		if err == nil {
+			return artist.Name
+		}
+	}
+
+	return ""
+}
+
+// formatAgo describes an elapsed time, such as "just now", "20 min ago" or
+// "3 h ago".
+func formatAgo(elapsed time.Duration) string {
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%d min ago", int(elapsed.Minutes()))
+	case elapsed < 48*time.Hour:
+		return fmt.Sprintf("%d h ago", int(elapsed.Hours()))
+	default:
+		return fmt.Sprintf("%d days ago", int(elapsed.Hours()/24))
+	}
+}
@@ -0,0 +1,372 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+)
+
+// maxPlaylistItems is the most items Spotify allows in a playlist.
+const maxPlaylistItems = 10000
+
+// Ways of combining playlists.
+const (
+	combineUnion        = "union"
+	combineIntersection = "intersection"
+	combineDifference   = "difference"
+	combineInterleave   = "interleave"
+)
+
+// combineSource is a playlist read for combining, with a key per item that
+// is shared by the items holding the same track.
+type combineSource struct {
+	playlist *spotify.FullPlaylist
+	items    []spotify.PlaylistItem
+	keys     []int
+}
+
+func combinePlaylistsTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"combine_playlists",
+		mcp.WithDescription("Combine playlists into a new playlist or an existing one. union takes the tracks of every playlist in turn, intersection the tracks of the first playlist that are in all the others, difference the tracks of the first playlist that are in none of the others, and interleave alternates between the playlists"),
+		mcp.WithString("Playlist IDs",
+			mcp.Required(),
+			mcp.Description("Comma-separated Spotify IDs of the playlists to combine, in order"),
+		),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("union, intersection, difference or interleave"),
+			mcp.Enum(combineUnion, combineIntersection, combineDifference, combineInterleave),
+		),
+		mcp.WithBoolean("dedupe",
+			mcp.Description("Add only one copy of each track, leaving out tracks the target playlist already has (default: true)"),
+		),
+		mcp.WithBoolean("exact_only",
+			mcp.Description("Only treat the same track ID as the same track, not the same recording under another ID or likely duplicates, as find_playlist_duplicates does (default: false)"),
+		),
+		mcp.WithString("Target Playlist ID",
+			mcp.Description("Existing playlist to add the tracks to, at the end (default: create a new playlist)"),
+		),
+		mcp.WithString("Name",
+			mcp.Description("Name of the new playlist (default: the source names joined by the operation)"),
+		),
+		mcp.WithString("Description",
+			mcp.Description("Description of the new playlist"),
+		),
+		mcp.WithBoolean("Public",
+			mcp.Description("Whether the new playlist should be public (default: false)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only show the combined tracks, without creating or changing a playlist (default: false)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  combinePlaylistsBehaviour,
+	}
+}
+
+func combinePlaylistsBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDsParam, err := tools.GetParamFromRequest(request, "Playlist IDs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist IDs: %w", err)
+	}
+
+	var sourceIDs []spotify.ID
+	for _, value := range tools.SplitCommaSeparated(playlistIDsParam) {
+		playlistID, err := parsePlaylistID(value)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID %q: %v", value, err)), nil
+		}
+		sourceIDs = append(sourceIDs, playlistID)
+	}
+	if len(sourceIDs) < 2 {
+		return mcp.NewToolResultError("Give at least two playlists to combine."), nil
+	}
+
+	operation, err := tools.GetParamFromRequest(request, "operation")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operation: %w", err)
+	}
+	operation = strings.ToLower(strings.TrimSpace(operation))
+	switch operation {
+	case combineUnion, combineIntersection, combineDifference, combineInterleave:
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("operation must be %q, %q, %q or %q.", combineUnion, combineIntersection, combineDifference, combineInterleave)), nil
+	}
+
+	dedupe, err := tools.GetBoolParamFromRequest(request, "dedupe")
+	if err != nil {
+		dedupe = true
+	}
+	exactOnly, _ := tools.GetBoolParamFromRequest(request, "exact_only")
+	dryRun, _ := tools.GetBoolParamFromRequest(request, "dry_run")
+
+	var targetID spotify.ID
+	if value, _ := tools.GetParamFromRequest(request, "Target Playlist ID"); strings.TrimSpace(value) != "" {
+		targetID, err = parsePlaylistID(value)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid target playlist ID: %v", err)), nil
+		}
+	}
+
+	if !dryRun && !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+	}
+
+	spotifyClient := readClient()
+	if spotifyClient == nil {
+		return mcp.NewToolResultError("Spotify client not initialized. Please use the spotify_login tool first."), nil
+	}
+
+	sources := make([]combineSource, len(sourceIDs))
+	for i, playlistID := range sourceIDs {
+		playlist, items, err := loadPlaylist(ctx, spotifyClient, playlistID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read playlist %s: %w", playlistID, err)
+		}
+		sources[i] = combineSource{playlist: playlist, items: items}
+	}
+
+	// The target's tracks take part in matching so that deduping leaves out
+	// tracks it already has.
+	var target *combineSource
+	if targetID != "" {
+		playlist, items, err := loadPlaylist(ctx, spotifyClient, targetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read target playlist: %w", err)
+		}
+		target = &combineSource{playlist: playlist, items: items}
+	}
+
+	assignTrackKeys(sources, target, exactOnly)
+
+	picked := combineItems(sources, operation)
+
+	seen := map[int]bool{}
+	if target != nil && dedupe {
+		for _, key := range target.keys {
+			seen[key] = true
+		}
+	}
+
+	var uris []spotify.URI
+	var combined []spotify.PlaylistItem
+	duplicates, unavailable := 0, 0
+	for _, pick := range picked {
+		item := sources[pick.source].items[pick.position]
+		uri := playlistItemURI(item)
+		if !addable(uri) {
+			unavailable++
+			continue
+		}
+
+		key := sources[pick.source].keys[pick.position]
+		if dedupe && seen[key] {
+			duplicates++
+			continue
+		}
+		seen[key] = true
+
+		uris = append(uris, uri)
+		combined = append(combined, item)
+	}
+
+	names := make([]string, len(sources))
+	response := fmt.Sprintf("Combined %d playlists with %s:\n", len(sources), operation)
+	for i, source := range sources {
+		names[i] = source.playlist.Name
+		response += fmt.Sprintf("- %s (%s): %d tracks\n", source.playlist.Name, source.playlist.ID, len(source.items))
+	}
+	response += fmt.Sprintf("Result: %d tracks", len(uris))
+	if duplicates > 0 {
+		if target != nil {
+			response += fmt.Sprintf(", leaving out %d duplicate(s) or track(s) already in %s", duplicates, target.playlist.Name)
+		} else {
+			response += fmt.Sprintf(", leaving out %d duplicate(s)", duplicates)
+		}
+	}
+	if unavailable > 0 {
+		response += fmt.Sprintf(", leaving out %d local file(s) or unavailable track(s)", unavailable)
+	}
+	response += ".\n"
+
+	existing := 0
+	if target != nil {
+		existing = len(target.items)
+	}
+	if existing+len(uris) > maxPlaylistItems {
+		response += fmt.Sprintf("\nThat is more than the %d tracks a playlist can hold.\n", maxPlaylistItems)
+		return mcp.NewToolResultError(response), nil
+	}
+
+	if dryRun {
+		response += "\nDry run: no playlist was created or changed.\n"
+		for i, item := range combined {
+			if i == previewLength {
+				response += fmt.Sprintf("... and %d more\n", len(combined)-previewLength)
+				break
+			}
+			response += fmt.Sprintf("%d. %s\n", i+1, playlistItemName(item))
+		}
+		return mcp.NewToolResultText(response), nil
+	}
+
+	if len(uris) == 0 {
+		response += "\nNothing to add.\n"
+		return mcp.NewToolResultText(response), nil
+	}
+
+	change := journalEntry{tool: "combine_playlists"}
+
+	if target == nil {
+		name, _ := tools.GetParamFromRequest(request, "Name")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = strings.Join(names, " "+operation+" ")
+		}
+		description, _ := tools.GetParamFromRequest(request, "Description")
+		isPublic, _ := tools.GetBoolParamFromRequest(request, "Public")
+
+		user, err := client.AuthenticatedSpotifyClient.CurrentUser(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current user: %w", err)
+		}
+
+		playlist, err := client.AuthenticatedSpotifyClient.CreatePlaylistForUser(ctx, user.ID, name, description, isPublic, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create playlist: %w", err)
+		}
+		targetID = playlist.ID
+		response += fmt.Sprintf("\nCreated playlist %s.\n", playlist.Name)
+
+		change.playlistName = playlist.Name
+		change.undoSummary = "Unfollow the created playlist, which removes it from your library"
+		change.undo = undoCreate(targetID)
+	} else {
+		change.playlistName = target.playlist.Name
+		change.snapshotBefore = target.playlist.SnapshotID
+	}
+
+	snapshotID, added, err := addItemsInBatches(ctx, targetID, uris, -1)
+
+	// Record whatever was added, so a partial add can be undone too.
+	if added > 0 {
+		if target != nil {
+			change.undoSummary = fmt.Sprintf("Remove the %d added track(s)", added)
+			change.undo = undoAdd(targetID, len(target.items), uris[:added])
+		}
+		change.playlistID = targetID
+		change.summary = fmt.Sprintf("Added %d track(s) combined with %s", added, operation)
+		change.snapshotAfter = snapshotID
+		recordChange(change)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to add tracks to playlist %s after adding %d of %d: %w", targetID, added, len(uris), err)
+	}
+
+	response += fmt.Sprintf("Successfully added %d tracks to the playlist!\n", added)
+	response += fmt.Sprintf("Playlist ID: %s\n", targetID)
+	response += fmt.Sprintf("New snapshot ID: %s\n", snapshotID)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// assignTrackKeys keys every item of the sources and the target, giving the
+// items find_playlist_duplicates would group together the same key.
+func assignTrackKeys(sources []combineSource, target *combineSource, exactOnly bool) {
+	lists := make([]*combineSource, 0, len(sources)+1)
+	for i := range sources {
+		lists = append(lists, &sources[i])
+	}
+	if target != nil {
+		lists = append(lists, target)
+	}
+
+	var all []spotify.PlaylistItem
+	for _, list := range lists {
+		all = append(all, list.items...)
+	}
+
+	keys := make([]int, len(all))
+	for i := range keys {
+		keys[i] = i
+	}
+	for _, group := range findDuplicates(all, exactOnly) {
+		for _, position := range group.positions {
+			keys[position] = group.positions[0]
+		}
+	}
+
+	offset := 0
+	for _, list := range lists {
+		list.keys = keys[offset : offset+len(list.items)]
+		offset += len(list.items)
+	}
+}
+
+// combinePick is an item of one of the sources.
+type combinePick struct {
+	source   int
+	position int
+}
+
+// combineItems picks the items operation produces, in order.
+func combineItems(sources []combineSource, operation string) []combinePick {
+	var picks []combinePick
+
+	switch operation {
+	case combineUnion:
+		for i, source := range sources {
+			for position := range source.items {
+				picks = append(picks, combinePick{source: i, position: position})
+			}
+		}
+
+	case combineInterleave:
+		for position := 0; ; position++ {
+			found := false
+			for i, source := range sources {
+				if position < len(source.items) {
+					picks = append(picks, combinePick{source: i, position: position})
+					found = true
+				}
+			}
+			if !found {
+				break
+			}
+		}
+
+	case combineIntersection, combineDifference:
+		// counts[key] is how many of the other sources have the track.
+		counts := map[int]int{}
+		for _, source := range sources[1:] {
+			inSource := map[int]bool{}
+			for _, key := range source.keys {
+				if !inSource[key] {
+					inSource[key] = true
+					counts[key]++
+				}
+			}
+		}
+
+		want := len(sources) - 1
+		if operation == combineDifference {
+			want = 0
+		}
+		for position, key := range sources[0].keys {
+			if counts[key] == want {
+				picks = append(picks, combinePick{source: 0, position: position})
+			}
+		}
+	}
+
+	return picks
+}
@@ -0,0 +1,392 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/matching"
+	"spotify-mcp/internal/server/tools"
+)
+
+// durationToleranceMs is how far apart the lengths of two tracks with the same
+// title and artist may be for them to count as the same recording.
+const durationToleranceMs = 3000
+
+// Reasons items are grouped as duplicates, from the most to the least certain.
+const (
+	duplicateExact  = "same track"
+	duplicateISRC   = "same recording (ISRC)"
+	duplicateLikely = "same title, artist and duration"
+)
+
+// duplicateGroup is a set of playlist positions holding the same track.
+type duplicateGroup struct {
+	reason    string
+	positions []int
+}
+
+func findPlaylistDuplicatesTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"find_playlist_duplicates",
+		mcp.WithDescription("Find duplicate tracks in a playlist: the same track ID more than once, and likely duplicates such as the same recording on an album and a single, matched by ISRC or by title, artist and duration. Reports the positions of every copy"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+		mcp.WithBoolean("exact_only",
+			mcp.Description("Only report the same track ID appearing more than once (default: false)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  findPlaylistDuplicatesBehaviour,
+	}
+}
+
+func findPlaylistDuplicatesBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	spotifyClient := readClient()
+	if spotifyClient == nil {
+		return mcp.NewToolResultError("Spotify client not initialized. Please use the spotify_login tool first."), nil
+	}
+
+	exactOnly, _ := tools.GetBoolParamFromRequest(request, "exact_only")
+
+	playlist, items, err := loadPlaylist(ctx, spotifyClient, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := findDuplicates(items, exactOnly)
+	if len(groups) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No duplicates found in %s (%d tracks).", playlist.Name, len(items))), nil
+	}
+
+	extra := 0
+	for _, group := range groups {
+		extra += len(group.positions) - 1
+	}
+
+	response := fmt.Sprintf("Found %d duplicate group(s) in %s, %d extra copies among %d tracks:\n\n", len(groups), playlist.Name, extra, len(items))
+	for i, group := range groups {
+		response += fmt.Sprintf("%d. %s - %s\n", i+1, playlistItemName(items[group.positions[0]]), group.reason)
+		for _, position := range group.positions {
+			response += "   " + formatDuplicateEntry(position, items[position]) + "\n"
+		}
+		response += "\n"
+	}
+
+	response += fmt.Sprintf("Snapshot ID: %s\n", playlist.SnapshotID)
+	response += "Use dedupe_playlist to remove the extra copies.\n"
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func dedupePlaylistTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"dedupe_playlist",
+		mcp.WithDescription("Remove duplicate tracks from a playlist, keeping one copy of each. Finds duplicates the same way as find_playlist_duplicates"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+		mcp.WithString("keep",
+			mcp.Description("Which copy to keep: \"earliest\" or \"latest\" added (default: earliest)"),
+			mcp.Enum("earliest", "latest"),
+		),
+		mcp.WithBoolean("exact_only",
+			mcp.Description("Only remove copies of the same track ID, not likely duplicates (default: false)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("List what would be removed without changing the playlist (default: false)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  dedupePlaylistBehaviour,
+	}
+}
+
+func dedupePlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	keep, _ := tools.GetParamFromRequest(request, "keep")
+	keep = strings.ToLower(strings.TrimSpace(keep))
+	if keep == "" {
+		keep = "earliest"
+	}
+	if keep != "earliest" && keep != "latest" {
+		return mcp.NewToolResultError("keep must be \"earliest\" or \"latest\"."), nil
+	}
+
+	exactOnly, _ := tools.GetBoolParamFromRequest(request, "exact_only")
+	dryRun, _ := tools.GetBoolParamFromRequest(request, "dry_run")
+
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+	}
+
+	playlist, items, err := loadPlaylist(ctx, client.AuthenticatedSpotifyClient, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := findDuplicates(items, exactOnly)
+	if len(groups) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No duplicates found in %s (%d tracks).", playlist.Name, len(items))), nil
+	}
+
+	var response string
+	var remove []int
+	for i, group := range groups {
+		kept, extras := splitDuplicateGroup(items, group, keep == "latest")
+		remove = append(remove, extras...)
+
+		response += fmt.Sprintf("%d. %s - %s\n", i+1, playlistItemName(items[kept]), group.reason)
+		response += "   Keep " + formatDuplicateEntry(kept, items[kept]) + "\n"
+		for _, position := range extras {
+			response += "   Remove " + formatDuplicateEntry(position, items[position]) + "\n"
+		}
+	}
+
+	if dryRun {
+		header := fmt.Sprintf("Dry run: would remove %d track(s) from %s, keeping the %s copy of %d track(s):\n\n", len(remove), playlist.Name, keep, len(groups))
+		return mcp.NewToolResultText(header + response), nil
+	}
+
+	snapshotID, removed, err := removePositionsInBatches(ctx, playlistID, items, remove, playlist.SnapshotID)
+
+	// Record whatever was removed, so a partial dedupe can be undone too.
+	if removed > 0 {
+		removedFrom := removedItems(items, removedPositions(remove, removed))
+		recordChange(journalEntry{
+			tool:           "dedupe_playlist",
+			playlistID:     playlist.ID,
+			playlistName:   playlist.Name,
+			summary:        fmt.Sprintf("Removed %d duplicate track(s)", removed),
+			snapshotBefore: playlist.SnapshotID,
+			snapshotAfter:  snapshotID,
+			undoSummary:    removeUndoSummary(removedFrom),
+			undo:           undoRemove(playlist.ID, removedFrom),
+		})
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove duplicates after removing %d of %d: %w", removed, len(remove), err)
+	}
+
+	header := fmt.Sprintf("Successfully removed %d duplicate track(s) from %s, keeping the %s copy:\n\n", removed, playlist.Name, keep)
+	response = header + response
+	response += fmt.Sprintf("\nNew snapshot ID: %s\n", snapshotID)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// findDuplicates groups the positions of items that hold the same track:
+// the same URI, or unless exactOnly, the same ISRC or the same normalized
+// title and main artist with lengths within durationToleranceMs. Groups are
+// ordered by their first position.
+func findDuplicates(items []spotify.PlaylistItem, exactOnly bool) []duplicateGroup {
+	parent := make([]int, len(items))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) {
+		a, b = find(a), find(b)
+		if a < b {
+			parent[b] = a
+		} else if b < a {
+			parent[a] = b
+		}
+	}
+
+	byURI := map[spotify.URI]int{}
+	byISRC := map[string]int{}
+	byTitle := map[string][]int{}
+
+	for i, item := range items {
+		uri := playlistItemURI(item)
+		if uri == "" {
+			continue
+		}
+		if first, ok := byURI[uri]; ok {
+			union(first, i)
+			continue
+		}
+		byURI[uri] = i
+
+		track := item.Track.Track
+		if exactOnly || track == nil || item.IsLocal {
+			continue
+		}
+
+		if isrc := strings.ToUpper(track.ExternalIDs["isrc"]); isrc != "" {
+			if first, ok := byISRC[isrc]; ok {
+				union(first, i)
+			} else {
+				byISRC[isrc] = i
+			}
+		}
+
+		title := matching.NormalizeTitle(track.Name)
+		if title == "" || len(track.Artists) == 0 {
+			continue
+		}
+		key := title + "\x00" + matching.Normalize(track.Artists[0].Name)
+		for _, j := range byTitle[key] {
+			if abs(int(track.Duration)-int(items[j].Track.Track.Duration)) <= durationToleranceMs {
+				union(j, i)
+				break
+			}
+		}
+		byTitle[key] = append(byTitle[key], i)
+	}
+
+	members := map[int][]int{}
+	for i := range items {
+		if playlistItemURI(items[i]) == "" {
+			continue
+		}
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+
+	var groups []duplicateGroup
+	for _, positions := range members {
+		if len(positions) < 2 {
+			continue
+		}
+		groups = append(groups, duplicateGroup{
+			reason:    duplicateReason(items, positions),
+			positions: positions,
+		})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].positions[0] < groups[j].positions[0]
+	})
+
+	return groups
+}
+
+// duplicateReason names the weakest link that holds a group together.
+func duplicateReason(items []spotify.PlaylistItem, positions []int) string {
+	uri := playlistItemURI(items[positions[0]])
+	sameURI := true
+	for _, position := range positions[1:] {
+		if playlistItemURI(items[position]) != uri {
+			sameURI = false
+			break
+		}
+	}
+	if sameURI {
+		return duplicateExact
+	}
+
+	isrc := ""
+	for _, position := range positions {
+		track := items[position].Track.Track
+		if track == nil || track.ExternalIDs["isrc"] == "" {
+			return duplicateLikely
+		}
+		if isrc == "" {
+			isrc = strings.ToUpper(track.ExternalIDs["isrc"])
+		} else if strings.ToUpper(track.ExternalIDs["isrc"]) != isrc {
+			return duplicateLikely
+		}
+	}
+
+	return duplicateISRC
+}
+
+// splitDuplicateGroup picks the copy to keep, the earliest or latest added
+// with position breaking ties, and returns it with the others.
+func splitDuplicateGroup(items []spotify.PlaylistItem, group duplicateGroup, keepLatest bool) (int, []int) {
+	ordered := append([]int(nil), group.positions...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := items[ordered[i]].AddedAt, items[ordered[j]].AddedAt
+		if a != b {
+			return a < b
+		}
+		return ordered[i] < ordered[j]
+	})
+
+	keepIndex := 0
+	if keepLatest {
+		keepIndex = len(ordered) - 1
+	}
+
+	kept := ordered[keepIndex]
+	var extras []int
+	for _, position := range group.positions {
+		if position != kept {
+			extras = append(extras, position)
+		}
+	}
+
+	return kept, extras
+}
+
+func formatDuplicateEntry(position int, item spotify.PlaylistItem) string {
+	line := fmt.Sprintf("position %d", position)
+	if track := item.Track.Track; track != nil {
+		if track.ID != "" {
+			line += fmt.Sprintf(": %s", track.ID)
+		} else {
+			line += ": local file"
+		}
+		if track.Album.Name != "" {
+			line += fmt.Sprintf(", %s", track.Album.Name)
+		}
+		line += fmt.Sprintf(", %d:%02d", int(track.Duration)/60000, int(track.Duration)/1000%60)
+	}
+	if item.AddedAt != "" {
+		line += fmt.Sprintf(", added %s", item.AddedAt)
+	}
+	if item.AddedBy.ID != "" {
+		addedBy := item.AddedBy.DisplayName
+		if addedBy == "" {
+			addedBy = item.AddedBy.ID
+		}
+		line += fmt.Sprintf(" by %s", addedBy)
+	}
+	return line
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,154 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/playlistfile"
+	"spotify-mcp/internal/server/tools"
+	"spotify-mcp/internal/storage"
+)
+
+func exportPlaylistTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"export_playlist",
+		mcp.WithDescription("Export every track of a playlist as CSV, extended M3U8, XSPF or JSPF, with track ID, URI, ISRC, title, artists, album, duration and when and by whom each track was added. Writes a file to the export directory, or returns the content inline"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+		mcp.WithString("format",
+			mcp.Description("File format: csv, m3u8, xspf or jspf (default: csv)"),
+			mcp.Enum(playlistfile.ExportFormats...),
+		),
+		mcp.WithBoolean("inline",
+			mcp.Description("Return the exported content instead of writing a file (default: false)"),
+		),
+		mcp.WithString("file_name",
+			mcp.Description("File name, or relative path, inside the export directory (default: the playlist name with the format's extension)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  exportPlaylistBehaviour,
+	}
+}
+
+func exportPlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	format, _ := tools.GetParamFromRequest(request, "format")
+	format = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), "."))
+	if format == "" {
+		format = playlistfile.FormatCSV
+	}
+	if format == "m3u" {
+		format = playlistfile.FormatM3U8
+	}
+
+	inline, _ := tools.GetBoolParamFromRequest(request, "inline")
+	fileName, _ := tools.GetParamFromRequest(request, "file_name")
+	fileName = strings.TrimSpace(fileName)
+
+	spotifyClient := readClient()
+	if spotifyClient == nil {
+		return mcp.NewToolResultError("Spotify client not initialized. Please use the spotify_login tool first."), nil
+	}
+
+	playlist, items, err := loadPlaylist(ctx, spotifyClient, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := exportedPlaylist(playlist, items)
+	data, err := playlistfile.Encode(format, exported)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not export the playlist: %v", err)), nil
+	}
+
+	if inline {
+		response := fmt.Sprintf("Exported %d tracks from %s as %s:\n\n", len(exported.Tracks), playlist.Name, strings.ToUpper(format))
+		response += string(data)
+		return mcp.NewToolResultText(response), nil
+	}
+
+	if fileName == "" {
+		fileName = playlistfile.FileName(playlist.Name, format)
+	}
+
+	path, err := storage.ExportPath(fileName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid file_name: %v", err)), nil
+	}
+
+	if err := storage.WriteFileAtomic(path, data); err != nil {
+		return nil, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	response := fmt.Sprintf("Exported %d tracks from %s as %s.\n", len(exported.Tracks), playlist.Name, strings.ToUpper(format))
+	response += fmt.Sprintf("File: %s\n", path)
+	response += fmt.Sprintf("Snapshot ID: %s\n", playlist.SnapshotID)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// exportedPlaylist converts a playlist and its items for playlistfile,
+// leaving out items Spotify no longer returns.
+func exportedPlaylist(playlist *spotify.FullPlaylist, items []spotify.PlaylistItem) playlistfile.Playlist {
+	owner := playlist.Owner.DisplayName
+	if owner == "" {
+		owner = playlist.Owner.ID
+	}
+
+	exported := playlistfile.Playlist{
+		Name:        playlist.Name,
+		Description: playlist.Description,
+		Owner:       owner,
+		URL:         playlist.ExternalURLs["spotify"],
+	}
+
+	for _, item := range items {
+		track := playlistfile.Track{
+			URI:     string(playlistItemURI(item)),
+			AddedAt: item.AddedAt,
+			AddedBy: item.AddedBy.DisplayName,
+		}
+		if track.AddedBy == "" {
+			track.AddedBy = item.AddedBy.ID
+		}
+
+		switch {
+		case item.Track.Track != nil:
+			full := item.Track.Track
+			track.ID = string(full.ID)
+			track.ISRC = full.ExternalIDs["isrc"]
+			track.Title = full.Name
+			track.Artists = trackArtistNames(full)
+			track.Album = full.Album.Name
+			track.DurationMs = int(full.Duration)
+		case item.Track.Episode != nil:
+			episode := item.Track.Episode
+			track.ID = string(episode.ID)
+			track.Title = episode.Name
+			track.DurationMs = int(episode.Duration_ms)
+		default:
+			continue
+		}
+
+		exported.Tracks = append(exported.Tracks, track)
+	}
+
+	return exported
+}
@@ -0,0 +1,271 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/playlistfile"
+	"spotify-mcp/internal/resolve"
+	"spotify-mcp/internal/server/tools"
+	"spotify-mcp/internal/storage"
+)
+
+const (
+	// maxImportEntries bounds the tracks read from one file, each of which
+	// costs a search or two.
+	maxImportEntries = 500
+	// maxImportFileBytes bounds the size of an imported file.
+	maxImportFileBytes = 5 * 1024 * 1024
+)
+
+func importPlaylistTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"import_playlist",
+		mcp.WithDescription("Import a track list from a CSV, M3U or plain text file (\"Artist - Title\" per line) into a new or existing playlist. Each line is matched by Spotify link, ISRC or a fuzzy title and artist search, with a confidence per match and a list of lines that couldn't be matched"),
+		mcp.WithString("path",
+			mcp.Description("Path of the file to import, relative to the export directory"),
+		),
+		mcp.WithString("content",
+			mcp.Description("The track list itself, instead of a path"),
+		),
+		mcp.WithString("format",
+			mcp.Description("csv, m3u or text (default: detected from the file name and content)"),
+			mcp.Enum(playlistfile.ImportFormats...),
+		),
+		mcp.WithString("Playlist ID",
+			mcp.Description("Playlist to append the tracks to. Leave out to create a new playlist"),
+		),
+		mcp.WithString("Name",
+			mcp.Description("Name of the new playlist (default: the file name)"),
+		),
+		mcp.WithString("Description",
+			mcp.Description("Description of the new playlist"),
+		),
+		mcp.WithBoolean("Public",
+			mcp.Description("Whether the new playlist should be public (default: false)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only show how the lines match, without creating or changing a playlist (default: false)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  importPlaylistBehaviour,
+	}
+}
+
+func importPlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, _ := tools.GetParamFromRequest(request, "path")
+	content, _ := tools.GetParamFromRequest(request, "content")
+	path = strings.TrimSpace(path)
+
+	var data []byte
+	switch {
+	case path != "" && strings.TrimSpace(content) != "":
+		return mcp.NewToolResultError("Provide either path or content, not both."), nil
+	case path != "":
+		file, err := storage.OpenExportFile(path)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Could not read the file: %v", err)), nil
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Could not read the file: %v", err)), nil
+		}
+		if info.Size() > maxImportFileBytes {
+			return mcp.NewToolResultError(fmt.Sprintf("The file is larger than %d MB.", maxImportFileBytes/1024/1024)), nil
+		}
+		data, err = io.ReadAll(io.LimitReader(file, maxImportFileBytes))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Could not read the file: %v", err)), nil
+		}
+	case strings.TrimSpace(content) != "":
+		data = []byte(content)
+	default:
+		return mcp.NewToolResultError("Provide the track list as path or content."), nil
+	}
+
+	format, _ := tools.GetParamFromRequest(request, "format")
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = playlistfile.DetectFormat(path, data)
+	}
+
+	entries, err := playlistfile.Parse(format, data)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not read the track list: %v", err)), nil
+	}
+	if len(entries) == 0 {
+		return mcp.NewToolResultText("The track list has no tracks."), nil
+	}
+	if len(entries) > maxImportEntries {
+		return mcp.NewToolResultText(fmt.Sprintf("The track list has %d tracks; at most %d can be imported at once.", len(entries), maxImportEntries)), nil
+	}
+
+	var playlistID spotify.ID
+	if value, _ := tools.GetParamFromRequest(request, "Playlist ID"); strings.TrimSpace(value) != "" {
+		playlistID, err = parsePlaylistID(value)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+		}
+	}
+
+	dryRun, _ := tools.GetBoolParamFromRequest(request, "dry_run")
+
+	if !dryRun && !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+	}
+
+	spotifyClient := readClient()
+	if spotifyClient == nil {
+		return mcp.NewToolResultError("Spotify client not initialized. Please use the spotify_login tool first."), nil
+	}
+
+	queries := make([]resolve.Query, len(entries))
+	for i, entry := range entries {
+		queries[i] = entryQuery(entry)
+	}
+	results := resolve.ResolveAll(ctx, spotifyClient, queries, resolve.DefaultWorkers)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var uris []spotify.URI
+	var matched, unresolved string
+	for i, entry := range entries {
+		result := results[i]
+		best, found := result.Best()
+		if !result.Resolved() {
+			unresolved += fmt.Sprintf("- line %d: %s", entry.Line, entry.Source)
+			if result.Err != nil {
+				unresolved += fmt.Sprintf(" (lookup failed: %v)", result.Err)
+			} else if found {
+				unresolved += fmt.Sprintf(" (closest: %s by %s, %.2f)", best.Title, strings.Join(best.Artists, ", "), best.Score)
+			}
+			unresolved += "\n"
+			continue
+		}
+
+		uris = append(uris, best.URI)
+		matched += fmt.Sprintf("- line %d: %s -> %s by %s [%s] (%.2f, %s)\n", entry.Line, entry.Source, best.Title, strings.Join(best.Artists, ", "), best.ID, best.Score, result.Method)
+	}
+
+	response := fmt.Sprintf("Matched %d of %d tracks:\n\n", len(uris), len(entries))
+	response += matched
+	if unresolved != "" {
+		response += fmt.Sprintf("\nUnresolved (%d):\n", len(entries)-len(uris))
+		response += unresolved
+	}
+
+	if dryRun {
+		response += "\nDry run: no playlist was created or changed.\n"
+		return mcp.NewToolResultText(response), nil
+	}
+
+	if len(uris) == 0 {
+		response += "\nNothing to add.\n"
+		return mcp.NewToolResultText(response), nil
+	}
+
+	change := journalEntry{tool: "import_playlist"}
+	insertedAt := 0
+
+	created := playlistID == ""
+	if created {
+		name, _ := tools.GetParamFromRequest(request, "Name")
+		name = strings.TrimSpace(name)
+		if name == "" && path != "" {
+			name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		if name == "" {
+			name = "Imported playlist"
+		}
+		description, _ := tools.GetParamFromRequest(request, "Description")
+		isPublic, _ := tools.GetBoolParamFromRequest(request, "Public")
+
+		user, err := client.AuthenticatedSpotifyClient.CurrentUser(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current user: %w", err)
+		}
+
+		playlist, err := client.AuthenticatedSpotifyClient.CreatePlaylistForUser(ctx, user.ID, name, description, isPublic, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create playlist: %w", err)
+		}
+		playlistID = playlist.ID
+		response += fmt.Sprintf("\nCreated playlist %s.\n", playlist.Name)
+
+		change.playlistName = playlist.Name
+		change.undoSummary = "Unfollow the created playlist, which removes it from your library"
+		change.undo = undoCreate(playlistID)
+	} else {
+		state, err := playlistState(ctx, playlistID)
+		if err != nil {
+			return nil, err
+		}
+
+		change.playlistName = state.Name
+		change.snapshotBefore = state.SnapshotID
+		insertedAt = int(state.Tracks.Total)
+	}
+
+	snapshotID, added, err := addItemsInBatches(ctx, playlistID, uris, -1)
+
+	// Record whatever was added, so a partial import can be undone too.
+	if added > 0 {
+		if !created {
+			change.undoSummary = fmt.Sprintf("Remove the %d imported track(s)", added)
+			change.undo = undoAdd(playlistID, insertedAt, uris[:added])
+		}
+		change.playlistID = playlistID
+		change.summary = fmt.Sprintf("Imported %d track(s)", added)
+		change.snapshotAfter = snapshotID
+		recordChange(change)
+	}
+
+	if err != nil && created && added == 0 {
+		// Don't leave an empty playlist behind.
+		if unfollowErr := client.AuthenticatedSpotifyClient.UnfollowPlaylist(ctx, playlistID); unfollowErr != nil {
+			return nil, fmt.Errorf("failed to add tracks to the new playlist %s, which was left empty because removing it failed too (%v): %w", playlistID, unfollowErr, err)
+		}
+		return nil, fmt.Errorf("failed to add tracks, so the new playlist was removed again: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add tracks to playlist %s after adding %d of %d: %w", playlistID, added, len(uris), err)
+	}
+
+	response += fmt.Sprintf("Successfully added %d tracks to the playlist!\n", added)
+	response += fmt.Sprintf("Playlist ID: %s\n", playlistID)
+	response += fmt.Sprintf("New snapshot ID: %s\n", snapshotID)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// entryQuery turns a parsed line into a query, keeping Spotify track links
+// as IDs.
+func entryQuery(entry playlistfile.Entry) resolve.Query {
+	query := resolve.Query{
+		ISRC:       entry.ISRC,
+		Title:      entry.Title,
+		Artist:     entry.Artist,
+		Album:      entry.Album,
+		DurationMs: entry.DurationMs,
+	}
+
+	if entry.URI != "" {
+		if itemType, id, err := tools.ParseSpotifyURI(entry.URI, "track"); err == nil && itemType == "track" {
+			query.ID = id
+		}
+	}
+
+	return query
+}
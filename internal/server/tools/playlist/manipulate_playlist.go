@@ -7,9 +7,13 @@ import (
 	"github.com/zmb3/spotify/v2"
 	"spotify-mcp/internal/client"
 	"spotify-mcp/internal/server/tools"
+	"strconv"
 	"strings"
 )
 
+// playlistBatchSize is the most tracks Spotify adds or removes per request.
+const playlistBatchSize = 100
+
 func PlaylistTools() []tools.ToolEntry {
 	return []tools.ToolEntry{
 		getPlaylistTool(),
@@ -17,7 +21,23 @@ func PlaylistTools() []tools.ToolEntry {
 		createPlaylistTool(),
 		addTracksToPlaylistTool(),
 		removeTracksFromPlaylistTool(),
+		reorderPlaylistTracksTool(),
 		getUserPlaylistsTool(),
+		updatePlaylistTool(),
+		setPlaylistCoverTool(),
+		findPlaylistDuplicatesTool(),
+		dedupePlaylistTool(),
+		sortPlaylistTool(),
+		reshufflePlaylistTool(),
+		exportPlaylistTool(),
+		importPlaylistTool(),
+		snapshotPlaylistTool(),
+		listPlaylistSnapshotsTool(),
+		diffPlaylistTool(),
+		restorePlaylistTool(),
+		combinePlaylistsTool(),
+		listRecentChangesTool(),
+		undoLastTool(),
 	}
 }
 
@@ -45,7 +65,7 @@ func getPlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mc
 
 	spotifyClient := client.SpotifyClient
 	if spotifyClient == nil {
-		return mcp.NewToolResultText("Spotify client not initialized. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Spotify client not initialized. Please use the spotify_login tool first."), nil
 	}
 
 	playlist, err := spotifyClient.GetPlaylist(ctx, spotify.ID(playlistID))
@@ -92,6 +112,12 @@ func getPlaylistTracksTool() tools.ToolEntry {
 		mcp.WithNumber("Offset",
 			mcp.Description("The index of the first track to return (default: 0)"),
 		),
+		mcp.WithBoolean("all",
+			mcp.Description("Fetch every track in the playlist, ignoring Limit and Offset (default: false)"),
+		),
+		mcp.WithNumber("max_items",
+			mcp.Description("Maximum number of tracks to return when all is set (default: no limit)"),
+		),
 	)
 
 	return tools.ToolEntry{
@@ -116,7 +142,27 @@ func getPlaylistTracksBehaviour(ctx context.Context, request mcp.CallToolRequest
 
 	spotifyClient := client.SpotifyClient
 	if spotifyClient == nil {
-		return mcp.NewToolResultText("Spotify client not initialized. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Spotify client not initialized. Please use the spotify_login tool first."), nil
+	}
+
+	all, _ := tools.GetBoolParamFromRequest(request, "all")
+	if all {
+		maxItems, _ := tools.GetIntParamFromRequest(request, "max_items")
+
+		items, err := client.FetchAll(ctx, client.PlaylistItemsFetcher(spotifyClient, spotify.ID(playlistID)), client.FetchAllOptions{
+			PageSize: 100,
+			MaxItems: maxItems,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get playlist tracks: %w", err)
+		}
+
+		response := fmt.Sprintf("All tracks in playlist (%d total):\n\n", len(items))
+		for i, item := range items {
+			response += formatPlaylistItem(i+1, item)
+		}
+
+		return mcp.NewToolResultText(response), nil
 	}
 
 	opts := []spotify.RequestOption{
@@ -133,48 +179,65 @@ func getPlaylistTracksBehaviour(ctx context.Context, request mcp.CallToolRequest
 		len(playlistItems.Items), playlistItems.Total)
 
 	for i, item := range playlistItems.Items {
-		if item.Track.Track == nil {
-			continue
-		}
+		response += formatPlaylistItem(i+offset+1, item)
+	}
 
-		track := item.Track.Track
+	if int(playlistItems.Total) > limit {
+		response += fmt.Sprintf("\nShowing tracks %d-%d of %d. Use the Offset parameter to see more tracks.",
+			offset+1, offset+len(playlistItems.Items), playlistItems.Total)
+	}
 
-		artists := ""
-		for j, artist := range track.Artists {
-			if j > 0 {
-				artists += ", "
-			}
-			artists += artist.Name
-		}
+	return mcp.NewToolResultText(response), nil
+}
 
-		trackNum := i + offset + 1
+func formatPlaylistItem(trackNum int, item spotify.PlaylistItem) string {
+	if item.Track.Track == nil {
+		return ""
+	}
 
-		response += fmt.Sprintf("%d. %s - %s\n", trackNum, track.Name, artists)
-		response += fmt.Sprintf("   Album: %s\n", track.Album.Name)
-		response += fmt.Sprintf("   Duration: %d ms\n", track.Duration)
-		response += fmt.Sprintf("   Track ID: %s\n", track.ID)
+	track := item.Track.Track
 
-		if item.AddedBy.ID != "" {
-			addedBy := item.AddedBy.DisplayName
-			if addedBy == "" {
-				addedBy = item.AddedBy.ID
-			}
-			response += fmt.Sprintf("   Added by: %s\n", addedBy)
+	artists := ""
+	for j, artist := range track.Artists {
+		if j > 0 {
+			artists += ", "
 		}
+		artists += artist.Name
+	}
+
+	response := fmt.Sprintf("%d. %s - %s\n", trackNum, track.Name, artists)
+	response += fmt.Sprintf("   Album: %s\n", track.Album.Name)
+	response += fmt.Sprintf("   Duration: %d ms\n", track.Duration)
+	response += fmt.Sprintf("   Track ID: %s\n", track.ID)
 
-		if item.AddedAt != "" {
-			response += fmt.Sprintf("   Added at: %s\n", item.AddedAt)
+	if item.AddedBy.ID != "" {
+		addedBy := item.AddedBy.DisplayName
+		if addedBy == "" {
+			addedBy = item.AddedBy.ID
 		}
+		response += fmt.Sprintf("   Added by: %s\n", addedBy)
+	}
 
-		response += "\n"
+	if item.AddedAt != "" {
+		response += fmt.Sprintf("   Added at: %s\n", item.AddedAt)
 	}
 
-	if int(playlistItems.Total) > limit {
-		response += fmt.Sprintf("\nShowing tracks %d-%d of %d. Use the Offset parameter to see more tracks.",
-			offset+1, offset+len(playlistItems.Items), playlistItems.Total)
+	response += "\n"
+
+	return response
+}
+
+// parsePlaylistID accepts a playlist ID, URI or URL.
+func parsePlaylistID(value string) (spotify.ID, error) {
+	itemType, id, err := tools.ParseSpotifyURI(value, "playlist")
+	if err != nil {
+		return "", err
+	}
+	if itemType != "playlist" {
+		return "", fmt.Errorf("%q is a %s, not a playlist", value, itemType)
 	}
 
-	return mcp.NewToolResultText(response), nil
+	return id, nil
 }
 
 func createPlaylistTool() tools.ToolEntry {
@@ -209,7 +272,7 @@ func createPlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (
 	}
 
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify for playlist creation. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist creation. Please use the spotify_login tool first."), nil
 	}
 
 	description, err := tools.GetParamFromRequest(request, "Description")
@@ -244,6 +307,16 @@ func createPlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (
 		return nil, fmt.Errorf("failed to create playlist: %w", err)
 	}
 
+	recordChange(journalEntry{
+		tool:          "create_playlist",
+		playlistID:    playlist.ID,
+		playlistName:  playlist.Name,
+		summary:       fmt.Sprintf("Created playlist %s", playlist.Name),
+		snapshotAfter: playlist.SnapshotID,
+		undoSummary:   "Unfollow the playlist, which removes it from your library",
+		undo:          undoCreate(playlist.ID),
+	})
+
 	response := fmt.Sprintf("Successfully created playlist!\n\n")
 	response += fmt.Sprintf("Name: %s\n", playlist.Name)
 	response += fmt.Sprintf("ID: %s\n", playlist.ID)
@@ -269,7 +342,10 @@ func addTracksToPlaylistTool() tools.ToolEntry {
 		),
 		mcp.WithString("Track IDs",
 			mcp.Required(),
-			mcp.Description("Comma-separated list of Spotify track IDs"),
+			mcp.Description("Comma-separated list of Spotify track IDs. More than 100 tracks are added in batches"),
+		),
+		mcp.WithNumber("Insert At",
+			mcp.Description("Zero-based position to insert the tracks at (default: end of the playlist)"),
 		),
 	)
 
@@ -291,7 +367,7 @@ func addTracksToPlaylistBehaviour(ctx context.Context, request mcp.CallToolReque
 	}
 
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
 	}
 
 	trackIDsList := strings.Split(trackIDsStr, ",")
@@ -307,36 +383,113 @@ func addTracksToPlaylistBehaviour(ctx context.Context, request mcp.CallToolReque
 	}
 
 	if len(trackIDs) == 0 {
-		return mcp.NewToolResultText("No valid track IDs provided."), nil
+		return mcp.NewToolResultError("No valid track IDs provided."), nil
 	}
 
-	if len(trackIDs) > 100 {
-		return mcp.NewToolResultText("Too many track IDs provided. Maximum is 100 tracks per request."), nil
+	position := -1
+	if insertAt, err := tools.GetIntParamFromRequest(request, "Insert At"); err == nil {
+		if insertAt < 0 {
+			return mcp.NewToolResultError("Insert At must be zero or a positive position."), nil
+		}
+		position = insertAt
 	}
 
-	snapshotID, err := client.AuthenticatedSpotifyClient.AddTracksToPlaylist(ctx, spotify.ID(playlistID), trackIDs...)
+	state, err := playlistState(ctx, spotify.ID(playlistID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to add tracks to playlist: %w", err)
+		return nil, err
+	}
+
+	uris := trackURIs(trackIDs)
+	snapshotID, added, err := addItemsInBatches(ctx, spotify.ID(playlistID), uris, position)
+
+	// Record whatever was added, so a partial add can be undone too.
+	if added > 0 {
+		insertedAt := position
+		if insertedAt < 0 {
+			insertedAt = int(state.Tracks.Total)
+		}
+		recordChange(journalEntry{
+			tool:           "add_tracks_to_playlist",
+			playlistID:     spotify.ID(playlistID),
+			playlistName:   state.Name,
+			summary:        fmt.Sprintf("Added %d track(s) at position %d", added, insertedAt),
+			snapshotBefore: state.SnapshotID,
+			snapshotAfter:  snapshotID,
+			undoSummary:    fmt.Sprintf("Remove the %d added track(s)", added),
+			undo:           undoAdd(spotify.ID(playlistID), insertedAt, uris[:added]),
+		})
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to add tracks to playlist after adding %d of %d: %w", added, len(trackIDs), err)
 	}
 
 	response := fmt.Sprintf("Successfully added %d tracks to the playlist!\n", len(trackIDs))
 	response += fmt.Sprintf("Playlist ID: %s\n", playlistID)
+	if position >= 0 {
+		response += fmt.Sprintf("Inserted at position: %d\n", position)
+	}
+	if batches := (len(trackIDs) + playlistBatchSize - 1) / playlistBatchSize; batches > 1 {
+		response += fmt.Sprintf("Batches: %d\n", batches)
+	}
 	response += fmt.Sprintf("New snapshot ID: %s\n", snapshotID)
 
 	return mcp.NewToolResultText(response), nil
 }
 
+// addItemsInBatches adds tracks or episodes to a playlist 100 at a time, the
+// most Spotify accepts per request. With a position of zero or more the items
+// are inserted there in order, otherwise they are appended. It returns the
+// last snapshot ID and how many items were added before any error.
+func addItemsInBatches(ctx context.Context, playlistID spotify.ID, uris []spotify.URI, position int) (string, int, error) {
+	var snapshotID string
+	added := 0
+
+	for start := 0; start < len(uris); start += playlistBatchSize {
+		batch := uris[start:min(start+playlistBatchSize, len(uris))]
+
+		batchPosition := -1
+		if position >= 0 {
+			batchPosition = position + start
+		}
+
+		var err error
+		snapshotID, err = client.AddItemsToPlaylist(ctx, playlistID, batchPosition, batch...)
+		if err != nil {
+			return snapshotID, added, err
+		}
+
+		added += len(batch)
+	}
+
+	return snapshotID, added, nil
+}
+
+// trackURIs converts track IDs to URIs.
+func trackURIs(trackIDs []spotify.ID) []spotify.URI {
+	uris := make([]spotify.URI, len(trackIDs))
+	for i, id := range trackIDs {
+		uris[i] = tools.ToSpotifyURI("track", id)
+	}
+	return uris
+}
+
 func removeTracksFromPlaylistTool() tools.ToolEntry {
 	toolDefinition := mcp.NewTool(
 		"remove_tracks_from_playlist",
-		mcp.WithDescription("Remove tracks from a playlist"),
+		mcp.WithDescription("Remove tracks from a playlist, either every occurrence of the given track IDs or the tracks at given positions"),
 		mcp.WithString("Playlist ID",
 			mcp.Required(),
 			mcp.Description("Spotify ID of the playlist"),
 		),
 		mcp.WithString("Track IDs",
-			mcp.Required(),
-			mcp.Description("Comma-separated list of Spotify track IDs to remove"),
+			mcp.Description("Comma-separated list of Spotify track IDs to remove. With Positions, one track ID per position"),
+		),
+		mcp.WithString("Positions",
+			mcp.Description("Comma-separated zero-based positions of the tracks to remove (max 100). Only those occurrences are removed"),
+		),
+		mcp.WithString("Snapshot ID",
+			mcp.Description("Snapshot ID the positions refer to. The removal fails instead of removing the wrong tracks if the playlist has changed since (default: the current snapshot)"),
 		),
 	)
 
@@ -352,13 +505,11 @@ func removeTracksFromPlaylistBehaviour(ctx context.Context, request mcp.CallTool
 		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
 	}
 
-	trackIDsStr, err := tools.GetParamFromRequest(request, "Track IDs")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get track IDs: %w", err)
-	}
+	trackIDsStr, _ := tools.GetParamFromRequest(request, "Track IDs")
+	positionsStr, _ := tools.GetParamFromRequest(request, "Positions")
 
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
 	}
 
 	trackIDsList := strings.Split(trackIDsStr, ",")
@@ -373,22 +524,191 @@ func removeTracksFromPlaylistBehaviour(ctx context.Context, request mcp.CallTool
 		}
 	}
 
+	if strings.TrimSpace(positionsStr) != "" {
+		return removePlaylistPositions(ctx, request, spotify.ID(playlistID), positionsStr, trackIDs)
+	}
+
 	if len(trackIDs) == 0 {
-		return mcp.NewToolResultText("No valid track IDs provided."), nil
+		return mcp.NewToolResultError("No valid track IDs provided."), nil
 	}
 
-	snapshotID, err := client.AuthenticatedSpotifyClient.RemoveTracksFromPlaylist(ctx, spotify.ID(playlistID), trackIDs...)
+	// Remove every occurrence by position, so the journal knows where each
+	// one was.
+	playlist, items, err := loadPlaylist(ctx, client.AuthenticatedSpotifyClient, spotify.ID(playlistID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to remove tracks from playlist: %w", err)
+		return nil, err
+	}
+
+	removing := map[spotify.URI]bool{}
+	for _, uri := range trackURIs(trackIDs) {
+		removing[uri] = true
+	}
+
+	var positions []int
+	for i, item := range items {
+		if removing[playlistItemURI(item)] {
+			positions = append(positions, i)
+		}
+	}
+
+	if len(positions) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("None of the tracks are in %s.", playlist.Name)), nil
+	}
+
+	snapshotID, removed, err := removePositionsInBatches(ctx, playlist.ID, items, positions, playlist.SnapshotID)
+
+	// Record whatever was removed, so a partial removal can be undone too.
+	if removed > 0 {
+		removedFrom := removedItems(items, removedPositions(positions, removed))
+		recordChange(journalEntry{
+			tool:           "remove_tracks_from_playlist",
+			playlistID:     playlist.ID,
+			playlistName:   playlist.Name,
+			summary:        fmt.Sprintf("Removed %d track(s)", removed),
+			snapshotBefore: playlist.SnapshotID,
+			snapshotAfter:  snapshotID,
+			undoSummary:    removeUndoSummary(removedFrom),
+			undo:           undoRemove(playlist.ID, removedFrom),
+		})
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove tracks from playlist after removing %d of %d: %w", removed, len(positions), err)
 	}
 
-	response := fmt.Sprintf("Successfully removed %d tracks from the playlist!\n", len(trackIDs))
+	response := fmt.Sprintf("Successfully removed %d tracks from the playlist!\n", removed)
 	response += fmt.Sprintf("Playlist ID: %s\n", playlistID)
 	response += fmt.Sprintf("New snapshot ID: %s\n", snapshotID)
 
 	return mcp.NewToolResultText(response), nil
 }
 
+// removePlaylistPositions removes the tracks at the given positions, pinned to
+// a snapshot so a playlist that changed in the meantime isn't edited at the
+// wrong places. Without track IDs, the tracks at the positions are looked up
+// in the current playlist.
+func removePlaylistPositions(ctx context.Context, request mcp.CallToolRequest, playlistID spotify.ID, positionsStr string, trackIDs []spotify.ID) (*mcp.CallToolResult, error) {
+	positions, err := parsePositions(positionsStr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid Positions: %v", err)), nil
+	}
+	if len(positions) > playlistBatchSize {
+		return mcp.NewToolResultError(fmt.Sprintf("Too many positions provided. Maximum is %d positions per request.", playlistBatchSize)), nil
+	}
+	if len(trackIDs) > 0 && len(trackIDs) != len(positions) {
+		return mcp.NewToolResultError(fmt.Sprintf("Got %d track IDs for %d positions. Give one track ID per position, or leave Track IDs out to remove whatever is at the positions.", len(trackIDs), len(positions))), nil
+	}
+
+	snapshotID, _ := tools.GetParamFromRequest(request, "Snapshot ID")
+	snapshotID = strings.TrimSpace(snapshotID)
+
+	var uris []spotify.URI
+	var playlistName, snapshotBefore string
+	var undo undoFunc
+	if len(trackIDs) > 0 {
+		uris = trackURIs(trackIDs)
+
+		state, err := playlistState(ctx, playlistID)
+		if err != nil {
+			return nil, err
+		}
+		playlistName = state.Name
+		snapshotBefore = state.SnapshotID
+
+		if snapshotID == "" {
+			// Pin the removal to the version just read, which the undo is
+			// based on.
+			snapshotID = state.SnapshotID
+		} else if snapshotID != state.SnapshotID {
+			undo, err = undoToCurrent(ctx, playlistID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		playlist, items, err := loadPlaylist(ctx, client.AuthenticatedSpotifyClient, playlistID)
+		if err != nil {
+			return nil, err
+		}
+		if snapshotID != "" && snapshotID != playlist.SnapshotID {
+			return mcp.NewToolResultError("The playlist has changed since that snapshot, so the tracks at those positions can't be looked up. Give one track ID per position to remove against the snapshot, or check the positions again with get_playlist_tracks."), nil
+		}
+		snapshotID = playlist.SnapshotID
+		playlistName = playlist.Name
+		snapshotBefore = playlist.SnapshotID
+
+		for _, position := range positions {
+			if position >= len(items) {
+				return mcp.NewToolResultError(fmt.Sprintf("Position %d is past the end of the playlist, which has %d tracks.", position, len(items))), nil
+			}
+			uri := playlistItemURI(items[position])
+			if uri == "" {
+				return mcp.NewToolResultError(fmt.Sprintf("The item at position %d can't be removed by position.", position)), nil
+			}
+			uris = append(uris, uri)
+		}
+	}
+
+	newSnapshotID, err := client.AuthenticatedSpotifyClient.RemoveTracksFromPlaylistOpt(ctx, playlistID, tracksToRemove(positions, uris), snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove tracks from playlist: %w", err)
+	}
+
+	removed := make([]removedItem, len(positions))
+	for i, position := range positions {
+		removed[i] = removedItem{position: position, uri: uris[i]}
+	}
+	undoSummary := removeUndoSummary(removed)
+	if undo == nil {
+		undo = undoRemove(playlistID, removed)
+	} else {
+		undoSummary = "Put the removed tracks back as they were before the removal"
+	}
+	recordChange(journalEntry{
+		tool:           "remove_tracks_from_playlist",
+		playlistID:     playlistID,
+		playlistName:   playlistName,
+		summary:        fmt.Sprintf("Removed %d track(s) by position", len(positions)),
+		snapshotBefore: snapshotBefore,
+		snapshotAfter:  newSnapshotID,
+		undoSummary:    undoSummary,
+		undo:           undo,
+	})
+
+	response := fmt.Sprintf("Successfully removed %d tracks from the playlist!\n", len(positions))
+	response += fmt.Sprintf("Playlist ID: %s\n", playlistID)
+	if snapshotID != "" {
+		response += fmt.Sprintf("Removed from snapshot ID: %s\n", snapshotID)
+	}
+	response += fmt.Sprintf("New snapshot ID: %s\n", newSnapshotID)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// parsePositions parses comma-separated zero-based playlist positions,
+// rejecting duplicates.
+func parsePositions(value string) ([]int, error) {
+	var positions []int
+	seen := map[int]bool{}
+	for _, part := range tools.SplitCommaSeparated(value) {
+		position, err := strconv.Atoi(part)
+		if err != nil || position < 0 {
+			return nil, fmt.Errorf("%q is not a zero-based position", part)
+		}
+		if seen[position] {
+			return nil, fmt.Errorf("position %d is listed twice", position)
+		}
+		seen[position] = true
+		positions = append(positions, position)
+	}
+
+	if len(positions) == 0 {
+		return nil, fmt.Errorf("no positions given")
+	}
+
+	return positions, nil
+}
+
 func getUserPlaylistsTool() tools.ToolEntry {
 	toolDefinition := mcp.NewTool(
 		"get_user_playlists",
@@ -402,6 +722,12 @@ func getUserPlaylistsTool() tools.ToolEntry {
 		mcp.WithNumber("Offset",
 			mcp.Description("The index of the first playlist to return (default: 0)"),
 		),
+		mcp.WithBoolean("all",
+			mcp.Description("Fetch every playlist for the user, ignoring Limit and Offset (default: false)"),
+		),
+		mcp.WithNumber("max_items",
+			mcp.Description("Maximum number of playlists to return when all is set (default: no limit)"),
+		),
 	)
 
 	return tools.ToolEntry{
@@ -412,7 +738,7 @@ func getUserPlaylistsTool() tools.ToolEntry {
 
 func getUserPlaylistsBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if !client.IsPlaybackAuthenticated() {
-		return mcp.NewToolResultText("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
+		return mcp.NewToolResultError("Not authenticated with Spotify. Please use the spotify_login tool first."), nil
 	}
 
 	userID, err := tools.GetParamFromRequest(request, "User ID")
@@ -436,6 +762,25 @@ func getUserPlaylistsBehaviour(ctx context.Context, request mcp.CallToolRequest)
 		limit = 20
 	}
 
+	all, _ := tools.GetBoolParamFromRequest(request, "all")
+	if all {
+		maxItems, _ := tools.GetIntParamFromRequest(request, "max_items")
+
+		playlists, err := client.FetchAll(ctx, client.UserPlaylistsFetcher(client.AuthenticatedSpotifyClient, userID), client.FetchAllOptions{
+			MaxItems: maxItems,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user playlists: %w", err)
+		}
+
+		response := fmt.Sprintf("All playlists for %s (%d total):\n\n", userID, len(playlists))
+		for i, playlist := range playlists {
+			response += formatUserPlaylist(i+1, playlist, userID)
+		}
+
+		return mcp.NewToolResultText(response), nil
+	}
+
 	opts := []spotify.RequestOption{
 		spotify.Limit(limit),
 		spotify.Offset(offset),
@@ -455,45 +800,49 @@ func getUserPlaylistsBehaviour(ctx context.Context, request mcp.CallToolRequest)
 		userDisplayText, len(playlists.Playlists), playlists.Total)
 
 	for i, playlist := range playlists.Playlists {
-		playlistNum := i + offset + 1
+		response += formatUserPlaylist(i+offset+1, playlist, userID)
+	}
 
-		owner := playlist.Owner.DisplayName
-		if owner == "" {
-			owner = playlist.Owner.ID
-		}
+	if int(playlists.Total) > limit {
+		response += fmt.Sprintf("\nShowing playlists %d-%d of %d. Use the Offset parameter to see more playlists.",
+			offset+1, offset+len(playlists.Playlists), playlists.Total)
+	}
 
-		response += fmt.Sprintf("%d. %s\n", playlistNum, playlist.Name)
-		if owner != userID && owner != "" {
-			response += fmt.Sprintf("   Owner: %s\n", owner)
-		}
-		response += fmt.Sprintf("   Tracks: %d\n", playlist.Tracks.Total)
-		response += fmt.Sprintf("   ID: %s\n", playlist.ID)
+	return mcp.NewToolResultText(response), nil
+}
 
-		if playlist.IsPublic {
-			response += "   Public: Yes\n"
-		} else {
-			response += "   Public: No\n"
-		}
+func formatUserPlaylist(playlistNum int, playlist spotify.SimplePlaylist, userID string) string {
+	owner := playlist.Owner.DisplayName
+	if owner == "" {
+		owner = playlist.Owner.ID
+	}
 
-		if playlist.Collaborative {
-			response += "   Collaborative: Yes\n"
-		}
+	response := fmt.Sprintf("%d. %s\n", playlistNum, playlist.Name)
+	if owner != userID && owner != "" {
+		response += fmt.Sprintf("   Owner: %s\n", owner)
+	}
+	response += fmt.Sprintf("   Tracks: %d\n", playlist.Tracks.Total)
+	response += fmt.Sprintf("   ID: %s\n", playlist.ID)
 
-		if playlist.Description != "" {
-			desc := playlist.Description
-			if len(desc) > 100 {
-				desc = desc[:97] + "..."
-			}
-			response += fmt.Sprintf("   Description: %s\n", desc)
-		}
+	if playlist.IsPublic {
+		response += "   Public: Yes\n"
+	} else {
+		response += "   Public: No\n"
+	}
 
-		response += "\n"
+	if playlist.Collaborative {
+		response += "   Collaborative: Yes\n"
 	}
 
-	if int(playlists.Total) > limit {
-		response += fmt.Sprintf("\nShowing playlists %d-%d of %d. Use the Offset parameter to see more playlists.",
-			offset+1, offset+len(playlists.Playlists), playlists.Total)
+	if playlist.Description != "" {
+		desc := playlist.Description
+		if len(desc) > 100 {
+			desc = desc[:97] + "..."
+		}
+		response += fmt.Sprintf("   Description: %s\n", desc)
 	}
 
-	return mcp.NewToolResultText(response), nil
+	response += "\n"
+
+	return response
 }
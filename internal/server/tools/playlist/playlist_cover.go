@@ -0,0 +1,314 @@
+package playlist
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+)
+
+const (
+	// maxCoverBytes is the largest JPEG Spotify accepts. The 256 KB limit
+	// applies to the base64 encoded upload, which is a third larger.
+	maxCoverBytes = 256 * 1024 * 3 / 4
+	// maxCoverInputBytes bounds the image read before shrinking it.
+	maxCoverInputBytes = 20 * 1024 * 1024
+	// minCoverSide is the smallest width or height a cover is scaled down to.
+	minCoverSide = 64
+)
+
+// coverQualities are the JPEG qualities tried at each size before the image
+// is scaled down further.
+var coverQualities = []int{90, 80, 70, 60}
+
+func setPlaylistCoverTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"set_playlist_cover",
+		mcp.WithDescription("Upload a JPEG image as the cover of a playlist you own. Images over Spotify's 256 KB limit are re-encoded and scaled down to fit"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+		mcp.WithString("File Path",
+			mcp.Description("Path of a local JPEG file"),
+		),
+		mcp.WithString("Image Base64",
+			mcp.Description("Base64 encoded JPEG image, optionally as a data: URI. Use instead of File Path"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  setPlaylistCoverBehaviour,
+	}
+}
+
+func setPlaylistCoverBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+	}
+
+	filePath, _ := tools.GetParamFromRequest(request, "File Path")
+	imageBase64, _ := tools.GetParamFromRequest(request, "Image Base64")
+	filePath = strings.TrimSpace(filePath)
+	imageBase64 = strings.TrimSpace(imageBase64)
+
+	var data []byte
+	switch {
+	case filePath != "" && imageBase64 != "":
+		return mcp.NewToolResultError("Provide either File Path or Image Base64, not both."), nil
+	case filePath != "":
+		data, err = readCoverFile(filePath)
+	case imageBase64 != "":
+		data, err = decodeCoverBase64(imageBase64)
+	default:
+		return mcp.NewToolResultError("Provide the image as File Path or Image Base64."), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not read the image: %v", err)), nil
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || format != "jpeg" {
+		return mcp.NewToolResultError("The image is not a valid JPEG. Spotify only accepts JPEG playlist covers."), nil
+	}
+
+	cover, err := fitCoverImage(data)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not fit the image under Spotify's 256 KB limit: %v", err)), nil
+	}
+
+	previous, err := client.AuthenticatedSpotifyClient.GetPlaylist(ctx, playlistID, spotify.Fields("id,name,snapshot_id,images"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist: %w", err)
+	}
+
+	if err := client.AuthenticatedSpotifyClient.SetPlaylistImage(ctx, playlistID, bytes.NewReader(cover)); err != nil {
+		return nil, fmt.Errorf("failed to upload playlist cover: %w", err)
+	}
+
+	// Spotify can't remove a cover, so an upload to a playlist without one
+	// has nothing to go back to and isn't journaled.
+	previousCover, hasPreviousCover := largestImage(previous.Images)
+	if hasPreviousCover {
+		// A cover upload doesn't change the snapshot, so the entry has no
+		// snapshotAfter to hold undoing back on.
+		recordChange(journalEntry{
+			tool:           "set_playlist_cover",
+			playlistID:     playlistID,
+			playlistName:   previous.Name,
+			summary:        "Uploaded a new cover",
+			snapshotBefore: previous.SnapshotID,
+			undoSummary:    "Upload the previous cover again",
+			undo:           undoCover(playlistID, previousCover.URL),
+		})
+	}
+
+	response := fmt.Sprintf("Successfully uploaded the playlist cover!\n\n")
+	response += fmt.Sprintf("Playlist ID: %s\n", playlistID)
+	if len(cover) == len(data) {
+		response += fmt.Sprintf("Image: %dx%d, %d KB\n", config.Width, config.Height, len(cover)/1024)
+	} else {
+		resized, _, _ := image.DecodeConfig(bytes.NewReader(cover))
+		response += fmt.Sprintf("Image: %dx%d, %d KB (re-encoded from %dx%d, %d KB)\n", resized.Width, resized.Height, len(cover)/1024, config.Width, config.Height, len(data)/1024)
+	}
+	response += "Spotify may take a few moments to show the new cover.\n"
+	if !hasPreviousCover {
+		response += "The playlist had no cover before, so this upload can't be undone.\n"
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// largestImage returns the widest of a playlist's images, which Spotify
+// lists in no guaranteed order.
+func largestImage(images []spotify.Image) (spotify.Image, bool) {
+	if len(images) == 0 {
+		return spotify.Image{}, false
+	}
+
+	largest := images[0]
+	for _, img := range images[1:] {
+		if img.Width > largest.Width {
+			largest = img
+		}
+	}
+	return largest, true
+}
+
+// undoCover uploads the cover at url again. A playlist without a cover of
+// its own shows a mosaic of its first albums, which comes back as a fixed
+// image.
+func undoCover(playlistID spotify.ID, url string) undoFunc {
+	return func(ctx context.Context, snapshotID string) (string, error) {
+		data, err := downloadCover(ctx, url)
+		if err != nil {
+			return "", err
+		}
+
+		cover, err := fitCoverImage(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to fit previous cover: %w", err)
+		}
+
+		if err := client.AuthenticatedSpotifyClient.SetPlaylistImage(ctx, playlistID, bytes.NewReader(cover)); err != nil {
+			return "", fmt.Errorf("failed to upload playlist cover: %w", err)
+		}
+		return snapshotID, nil
+	}
+}
+
+// downloadCover fetches a cover image from Spotify's image CDN.
+func downloadCover(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download previous cover: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download previous cover: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCoverInputBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download previous cover: %w", err)
+	}
+	return data, nil
+}
+
+func readCoverFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxCoverInputBytes {
+		return nil, fmt.Errorf("%s is larger than %d MB", path, maxCoverInputBytes/1024/1024)
+	}
+
+	return os.ReadFile(path)
+}
+
+// decodeCoverBase64 decodes base64 image data, dropping a data: URI prefix
+// and any line breaks.
+func decodeCoverBase64(value string) ([]byte, error) {
+	if strings.HasPrefix(value, "data:") {
+		comma := strings.Index(value, ",")
+		if comma < 0 {
+			return nil, fmt.Errorf("invalid data URI")
+		}
+		value = value[comma+1:]
+	}
+
+	value = strings.Join(strings.Fields(value), "")
+	value = strings.TrimRight(value, "=")
+	if len(value) > maxCoverInputBytes*4/3 {
+		return nil, fmt.Errorf("image is larger than %d MB", maxCoverInputBytes/1024/1024)
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+
+	return data, nil
+}
+
+// fitCoverImage returns the JPEG unchanged if it is small enough, and
+// otherwise re-encodes it at lower qualities, scaling it down by a quarter
+// each time none of them fit.
+func fitCoverImage(data []byte) ([]byte, error) {
+	if len(data) <= maxCoverBytes {
+		return data, nil
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JPEG: %w", err)
+	}
+
+	for {
+		for _, quality := range coverQualities {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, fmt.Errorf("failed to encode JPEG: %w", err)
+			}
+			if buf.Len() <= maxCoverBytes {
+				return buf.Bytes(), nil
+			}
+		}
+
+		bounds := img.Bounds()
+		width, height := bounds.Dx()*3/4, bounds.Dy()*3/4
+		if width < minCoverSide || height < minCoverSide {
+			return nil, fmt.Errorf("image is still too large at %dx%d", bounds.Dx(), bounds.Dy())
+		}
+		img = scaleImage(img, width, height)
+	}
+}
+
+// scaleImage shrinks img to width by height, averaging the source pixels
+// that fall on each target pixel.
+func scaleImage(img image.Image, width, height int) image.Image {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := y * srcHeight / height
+		y1 := max((y+1)*srcHeight/height, y0+1)
+
+		for x := 0; x < width; x++ {
+			x0 := x * srcWidth / width
+			x1 := max((x+1)*srcWidth/width, x0+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			scaled.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return scaled
+}
@@ -0,0 +1,129 @@
+package playlist
+
+import (
+	"fmt"
+	"sort"
+
+	"spotify-mcp/internal/snapshot"
+)
+
+// itemChange is an item added at or removed from a position.
+type itemChange struct {
+	position int
+	item     snapshot.Item
+}
+
+// itemMove is an item that changed position.
+type itemMove struct {
+	from int
+	to   int
+	item snapshot.Item
+}
+
+// playlistDiff lists what changed between two versions of a playlist.
+// Removed positions refer to the old version, added and moved ones to the
+// new.
+type playlistDiff struct {
+	added   []itemChange
+	removed []itemChange
+	moved   []itemMove
+}
+
+// diffItems compares two versions of a playlist. The k-th copy of a URI in
+// the old version is paired with the k-th copy in the new; unpaired items
+// were removed or added. Of the paired items, those on a longest run still
+// in the same relative order stayed put and the rest were moved, which is
+// the fewest moves that explain the new order.
+func diffItems(from, to []snapshot.Item) playlistDiff {
+	var diff playlistDiff
+
+	positions := map[string][]int{}
+	for i, item := range to {
+		positions[item.URI] = append(positions[item.URI], i)
+	}
+
+	paired := make([]bool, len(to))
+	var pairs []itemMove
+	for i, item := range from {
+		if len(positions[item.URI]) == 0 {
+			diff.removed = append(diff.removed, itemChange{position: i, item: item})
+			continue
+		}
+		j := positions[item.URI][0]
+		positions[item.URI] = positions[item.URI][1:]
+		paired[j] = true
+		pairs = append(pairs, itemMove{from: i, to: j, item: to[j]})
+	}
+
+	for j, item := range to {
+		if !paired[j] {
+			diff.added = append(diff.added, itemChange{position: j, item: item})
+		}
+	}
+
+	seq := make([]int, len(pairs))
+	for i, pair := range pairs {
+		seq[i] = pair.to
+	}
+	stays := longestIncreasing(seq)
+	for i, pair := range pairs {
+		if !stays[i] {
+			diff.moved = append(diff.moved, pair)
+		}
+	}
+	sort.Slice(diff.moved, func(i, j int) bool { return diff.moved[i].to < diff.moved[j].to })
+
+	return diff
+}
+
+func formatPlaylistDiff(from, to snapshot.Snapshot, diff playlistDiff) string {
+	var response string
+	if from.Name != to.Name {
+		response += fmt.Sprintf("Name: %q -> %q\n", from.Name, to.Name)
+	}
+	if from.Description != to.Description {
+		response += fmt.Sprintf("Description: %q -> %q\n", from.Description, to.Description)
+	}
+	if from.Public != to.Public {
+		response += fmt.Sprintf("Public: %t -> %t\n", from.Public, to.Public)
+	}
+	if from.Collaborative != to.Collaborative {
+		response += fmt.Sprintf("Collaborative: %t -> %t\n", from.Collaborative, to.Collaborative)
+	}
+	response += fmt.Sprintf("Tracks: %d -> %d. %d added, %d removed, %d moved.\n", len(from.Items), len(to.Items), len(diff.added), len(diff.removed), len(diff.moved))
+
+	if len(diff.added) > 0 {
+		response += "\nAdded:\n"
+		for i, change := range diff.added {
+			if i == previewLength {
+				response += fmt.Sprintf("... and %d more\n", len(diff.added)-previewLength)
+				break
+			}
+			response += fmt.Sprintf("+ %d. %s (%s)\n", change.position, snapshotItemName(change.item), change.item.URI)
+		}
+	}
+
+	if len(diff.removed) > 0 {
+		response += "\nRemoved:\n"
+		for i, change := range diff.removed {
+			if i == previewLength {
+				response += fmt.Sprintf("... and %d more\n", len(diff.removed)-previewLength)
+				break
+			}
+			response += fmt.Sprintf("- %d. %s (%s)\n", change.position, snapshotItemName(change.item), change.item.URI)
+		}
+	}
+
+	if len(diff.moved) > 0 {
+		response += "\nMoved:\n"
+		for i, move := range diff.moved {
+			if i == previewLength {
+				response += fmt.Sprintf("... and %d more\n", len(diff.moved)-previewLength)
+				break
+			}
+			response += fmt.Sprintf("~ %s: %d -> %d\n", snapshotItemName(move.item), move.from, move.to)
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,118 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+)
+
+// readClient returns the client for reading playlists, preferring the user's
+// so that private playlists can be read too.
+func readClient() *spotify.Client {
+	if client.IsPlaybackAuthenticated() {
+		return client.AuthenticatedSpotifyClient
+	}
+	return client.SpotifyClient
+}
+
+// loadPlaylist fetches a playlist's name and snapshot ID and then every item
+// in it. Positions refer to the returned snapshot unless the playlist
+// changed between the two requests, which pinned edits detect.
+func loadPlaylist(ctx context.Context, spotifyClient *spotify.Client, playlistID spotify.ID) (*spotify.FullPlaylist, []spotify.PlaylistItem, error) {
+	playlist, err := spotifyClient.GetPlaylist(ctx, playlistID, spotify.Fields("id,name,description,public,collaborative,snapshot_id,owner,external_urls"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get playlist: %w", err)
+	}
+
+	items, err := client.FetchAll(ctx, client.PlaylistItemsFetcher(spotifyClient, playlistID), client.FetchAllOptions{
+		PageSize: 100,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get playlist tracks: %w", err)
+	}
+
+	return playlist, items, nil
+}
+
+// playlistItemURI returns the URI of a playlist item's track or episode,
+// including local files.
+func playlistItemURI(item spotify.PlaylistItem) spotify.URI {
+	switch {
+	case item.Track.Track != nil:
+		return item.Track.Track.URI
+	case item.Track.Episode != nil:
+		return item.Track.Episode.URI
+	default:
+		return ""
+	}
+}
+
+// playlistItemName describes an item as "Title by Artist, Artist".
+func playlistItemName(item spotify.PlaylistItem) string {
+	switch {
+	case item.Track.Track != nil:
+		return fmt.Sprintf("%s by %s", item.Track.Track.Name, strings.Join(trackArtistNames(item.Track.Track), ", "))
+	case item.Track.Episode != nil:
+		return item.Track.Episode.Name
+	default:
+		return "Unavailable item"
+	}
+}
+
+func trackArtistNames(track *spotify.FullTrack) []string {
+	names := make([]string, len(track.Artists))
+	for i, artist := range track.Artists {
+		names[i] = artist.Name
+	}
+	return names
+}
+
+// tracksToRemove groups positions by URI, the way Spotify wants them.
+func tracksToRemove(positions []int, uris []spotify.URI) []spotify.TrackToRemove {
+	var tracks []spotify.TrackToRemove
+	index := map[spotify.URI]int{}
+	for i, uri := range uris {
+		j, ok := index[uri]
+		if !ok {
+			j = len(tracks)
+			index[uri] = j
+			tracks = append(tracks, spotify.TrackToRemove{URI: string(uri)})
+		}
+		tracks[j].Positions = append(tracks[j].Positions, positions[i])
+	}
+	return tracks
+}
+
+// removePositionsInBatches removes the items at positions, 100 per request.
+// Batches start from the end of the playlist so the remaining positions stay
+// valid, and each one is pinned to the snapshot left by the one before,
+// starting at snapshotID. It returns the last snapshot ID and how many items
+// were removed before any error.
+func removePositionsInBatches(ctx context.Context, playlistID spotify.ID, items []spotify.PlaylistItem, positions []int, snapshotID string) (string, int, error) {
+	sorted := append([]int(nil), positions...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+	removed := 0
+	for start := 0; start < len(sorted); start += playlistBatchSize {
+		batch := sorted[start:min(start+playlistBatchSize, len(sorted))]
+
+		uris := make([]spotify.URI, len(batch))
+		for i, position := range batch {
+			uris[i] = playlistItemURI(items[position])
+		}
+
+		newSnapshotID, err := client.AuthenticatedSpotifyClient.RemoveTracksFromPlaylistOpt(ctx, playlistID, tracksToRemove(batch, uris), snapshotID)
+		if err != nil {
+			return snapshotID, removed, err
+		}
+
+		snapshotID = newSnapshotID
+		removed += len(batch)
+	}
+
+	return snapshotID, removed, nil
+}
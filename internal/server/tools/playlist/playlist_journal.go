@@ -0,0 +1,399 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/snapshot"
+)
+
+// maxJournalChanges is how many playlist changes the journal keeps; older
+// ones can no longer be undone.
+const maxJournalChanges = 50
+
+var (
+	// journal holds the playlist changes made this session, oldest first.
+	journal      []journalEntry
+	journalMutex sync.Mutex
+	nextChangeID = 1
+)
+
+// undoFunc reverts a change. snapshotID is the playlist's current snapshot,
+// which edits are pinned to where Spotify allows it. It returns the
+// playlist's new snapshot ID, or an empty string if the playlist is gone.
+type undoFunc func(ctx context.Context, snapshotID string) (string, error)
+
+// journalEntry is a change made by a playlist tool and how to revert it.
+type journalEntry struct {
+	id           int
+	time         time.Time
+	tool         string
+	playlistID   spotify.ID
+	playlistName string
+	summary      string
+	// snapshotBefore and snapshotAfter are the playlist's snapshot IDs around
+	// the change. Undoing is refused while the playlist's current snapshot
+	// differs from snapshotAfter, since someone else changed it since.
+	snapshotBefore string
+	snapshotAfter  string
+	undoSummary    string
+	undo           undoFunc
+}
+
+func (e journalEntry) playlistLabel() string {
+	if e.playlistName == "" {
+		return string(e.playlistID)
+	}
+	return fmt.Sprintf("%s (%s)", e.playlistName, e.playlistID)
+}
+
+// recordChange adds a change to the journal, dropping the oldest beyond
+// maxJournalChanges.
+func recordChange(entry journalEntry) {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	entry.id = nextChangeID
+	nextChangeID++
+	entry.time = time.Now()
+
+	journal = append(journal, entry)
+	if len(journal) > maxJournalChanges {
+		journal = journal[len(journal)-maxJournalChanges:]
+	}
+}
+
+// lastChange returns the newest change, to playlistID if it isn't empty.
+func lastChange(playlistID spotify.ID) (journalEntry, bool) {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	for i := len(journal) - 1; i >= 0; i-- {
+		if playlistID == "" || journal[i].playlistID == playlistID {
+			return journal[i], true
+		}
+	}
+	return journalEntry{}, false
+}
+
+// recentChanges returns the journal, newest first.
+func recentChanges() []journalEntry {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	entries := make([]journalEntry, len(journal))
+	for i, entry := range journal {
+		entries[len(journal)-1-i] = entry
+	}
+	return entries
+}
+
+// completeUndo drops an undone change. The playlist is back in the state
+// the change started from, so the change before it on the same playlist,
+// which ended in that state, now ends at newSnapshotID instead.
+func completeUndo(undone journalEntry, newSnapshotID string) {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	for i := len(journal) - 1; i >= 0; i-- {
+		if journal[i].id == undone.id {
+			journal = append(journal[:i], journal[i+1:]...)
+			break
+		}
+	}
+
+	for i := len(journal) - 1; i >= 0; i-- {
+		if journal[i].playlistID != undone.playlistID {
+			continue
+		}
+		if journal[i].snapshotAfter == undone.snapshotBefore {
+			journal[i].snapshotAfter = newSnapshotID
+		}
+		break
+	}
+}
+
+// playlistState fetches the playlist's name, snapshot ID and number of items.
+func playlistState(ctx context.Context, playlistID spotify.ID) (*spotify.FullPlaylist, error) {
+	playlist, err := client.AuthenticatedSpotifyClient.GetPlaylist(ctx, playlistID, spotify.Fields("id,name,snapshot_id,tracks.total"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist: %w", err)
+	}
+	return playlist, nil
+}
+
+// removedItem is an item removed from a playlist and where it was.
+type removedItem struct {
+	position int
+	uri      spotify.URI
+}
+
+// removedItems lists the items at positions, for undoRemove.
+func removedItems(items []spotify.PlaylistItem, positions []int) []removedItem {
+	removed := make([]removedItem, len(positions))
+	for i, position := range positions {
+		removed[i] = removedItem{position: position, uri: playlistItemURI(items[position])}
+	}
+	return removed
+}
+
+// undoAdd removes the items with uris inserted at position, after checking
+// that they are still there.
+func undoAdd(playlistID spotify.ID, position int, uris []spotify.URI) undoFunc {
+	return func(ctx context.Context, snapshotID string) (string, error) {
+		playlist, items, err := loadPlaylist(ctx, client.AuthenticatedSpotifyClient, playlistID)
+		if err != nil {
+			return "", err
+		}
+
+		positions := make([]int, len(uris))
+		for i, uri := range uris {
+			positions[i] = position + i
+			if positions[i] >= len(items) || playlistItemURI(items[positions[i]]) != uri {
+				return "", fmt.Errorf("the added tracks are no longer at positions %d to %d", position, position+len(uris)-1)
+			}
+		}
+
+		newSnapshotID, removed, err := removePositionsInBatches(ctx, playlistID, items, positions, playlist.SnapshotID)
+		if err != nil {
+			return "", fmt.Errorf("failed to remove tracks after removing %d of %d: %w", removed, len(positions), err)
+		}
+		return newSnapshotID, nil
+	}
+}
+
+// removedPositions returns the positions removePositionsInBatches removed
+// before it failed, having removed the given number. It works from the end of
+// the playlist, so those are the highest positions, and the positions before
+// them are unchanged.
+func removedPositions(positions []int, removed int) []int {
+	sorted := append([]int(nil), positions...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	return sorted[:removed]
+}
+
+// undoRemove puts removed items back at their original positions. Inserting
+// them in ascending order of position restores each one exactly. Local files
+// can't be added back and are left out, shifting the positions after them.
+func undoRemove(playlistID spotify.ID, removed []removedItem) undoFunc {
+	return func(ctx context.Context, snapshotID string) (string, error) {
+		sorted := append([]removedItem(nil), removed...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].position < sorted[j].position })
+
+		newSnapshotID := snapshotID
+		skipped := 0
+		for start := 0; start < len(sorted); {
+			if !addable(sorted[start].uri) {
+				skipped++
+				start++
+				continue
+			}
+
+			// Add runs of consecutive positions in one go.
+			end := start + 1
+			for end < len(sorted) && sorted[end].position == sorted[end-1].position+1 && addable(sorted[end].uri) {
+				end++
+			}
+
+			uris := make([]spotify.URI, end-start)
+			for i := range uris {
+				uris[i] = sorted[start+i].uri
+			}
+
+			var err error
+			newSnapshotID, _, err = addItemsInBatches(ctx, playlistID, uris, sorted[start].position-skipped)
+			if err != nil {
+				return "", fmt.Errorf("failed to add tracks back: %w", err)
+			}
+			start = end
+		}
+
+		return newSnapshotID, nil
+	}
+}
+
+// removeUndoSummary describes undoRemove for removed.
+func removeUndoSummary(removed []removedItem) string {
+	summary := fmt.Sprintf("Add the %d removed track(s) back at their positions", len(removed))
+	skipped := 0
+	for _, item := range removed {
+		if !addable(item.uri) {
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		summary += fmt.Sprintf(", except %d local file(s) or unavailable track(s)", skipped)
+	}
+	return summary
+}
+
+// undoReorder moves a range moved by reorder_playlist_tracks back.
+func undoReorder(playlistID spotify.ID, rangeStart, rangeLength, insertBefore int) undoFunc {
+	return func(ctx context.Context, snapshotID string) (string, error) {
+		options := spotify.PlaylistReorderOptions{
+			RangeStart:   spotify.Numeric(insertBefore),
+			RangeLength:  spotify.Numeric(rangeLength),
+			InsertBefore: spotify.Numeric(rangeStart + rangeLength),
+			SnapshotID:   snapshotID,
+		}
+		if insertBefore > rangeStart {
+			options.RangeStart = spotify.Numeric(insertBefore - rangeLength)
+			options.InsertBefore = spotify.Numeric(rangeStart)
+		}
+
+		newSnapshotID, err := client.AuthenticatedSpotifyClient.ReorderPlaylistTracks(ctx, playlistID, options)
+		if err != nil {
+			return "", fmt.Errorf("failed to reorder playlist tracks: %w", err)
+		}
+		return newSnapshotID, nil
+	}
+}
+
+// undoOrder puts a playlist rearranged into order, as applied by
+// applyPlaylistOrder, back in its previous order.
+func undoOrder(playlistID spotify.ID, order []int) undoFunc {
+	return func(ctx context.Context, snapshotID string) (string, error) {
+		playlist, items, err := loadPlaylist(ctx, client.AuthenticatedSpotifyClient, playlistID)
+		if err != nil {
+			return "", err
+		}
+		if len(items) != len(order) {
+			return "", fmt.Errorf("the playlist has %d tracks instead of %d", len(items), len(order))
+		}
+
+		// The item now at position i was at order[i].
+		previous := make([]int, len(order))
+		for i, position := range order {
+			previous[position] = i
+		}
+
+		result, err := applyPlaylistOrder(ctx, playlistID, playlistItemURIs(items), canReplace(items), previous, playlist.SnapshotID, orderMethodAuto)
+		if err != nil {
+			return "", err
+		}
+		return result.snapshotID, nil
+	}
+}
+
+// undoRestore returns a playlist to a saved snapshot, for changes that
+// rewrote it more than a few positions can describe.
+func undoRestore(playlistID spotify.ID, saved snapshot.Snapshot) undoFunc {
+	return func(ctx context.Context, snapshotID string) (string, error) {
+		playlist, items, err := loadPlaylist(ctx, client.AuthenticatedSpotifyClient, playlistID)
+		if err != nil {
+			return "", err
+		}
+
+		diff := diffItems(snapshotItems(items), saved.Items)
+		result, err := restoreItems(ctx, playlistID, items, saved.Items, diff, playlist.SnapshotID, orderMethodAuto)
+		if err != nil {
+			return "", err
+		}
+
+		current := playlistDetails{
+			name:          playlist.Name,
+			description:   playlist.Description,
+			public:        playlist.IsPublic,
+			collaborative: playlist.Collaborative,
+		}
+		if current == snapshotDetails(saved) {
+			return result.snapshotID, nil
+		}
+
+		return restoreDetails(ctx, playlistID, current, snapshotDetails(saved))
+	}
+}
+
+// undoToCurrent reads a playlist and returns an undo that puts it back the way
+// it is now. It is for changes made against an older snapshot, whose
+// positions don't say where the items are in the current version.
+func undoToCurrent(ctx context.Context, playlistID spotify.ID) (undoFunc, error) {
+	playlist, items, err := loadPlaylist(ctx, client.AuthenticatedSpotifyClient, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return undoRestore(playlistID, playlistSnapshot(playlist, items, "")), nil
+}
+
+// undoCreate unfollows a created playlist, which is how Spotify deletes
+// playlists.
+func undoCreate(playlistID spotify.ID) undoFunc {
+	return func(ctx context.Context, snapshotID string) (string, error) {
+		if err := client.AuthenticatedSpotifyClient.UnfollowPlaylist(ctx, playlistID); err != nil {
+			return "", fmt.Errorf("failed to unfollow playlist: %w", err)
+		}
+		return "", nil
+	}
+}
+
+// playlistDetails are the settings update_playlist changes.
+type playlistDetails struct {
+	name          string
+	description   string
+	public        bool
+	collaborative bool
+}
+
+// undoUpdate sets a playlist's details back to previous.
+func undoUpdate(playlistID spotify.ID, previous playlistDetails) undoFunc {
+	return func(ctx context.Context, snapshotID string) (string, error) {
+		playlist, err := client.AuthenticatedSpotifyClient.GetPlaylist(ctx, playlistID, spotify.Fields("name,description,public,collaborative"))
+		if err != nil {
+			return "", fmt.Errorf("failed to get playlist: %w", err)
+		}
+
+		return restoreDetails(ctx, playlistID, playlistDetails{
+			name:          playlist.Name,
+			description:   playlist.Description,
+			public:        playlist.IsPublic,
+			collaborative: playlist.Collaborative,
+		}, previous)
+	}
+}
+
+// restoreDetails changes the details that differ between current and
+// previous back, and returns the playlist's new snapshot ID.
+func restoreDetails(ctx context.Context, playlistID spotify.ID, current, previous playlistDetails) (string, error) {
+	spotifyClient := client.AuthenticatedSpotifyClient
+
+	if current.name != previous.name {
+		if err := spotifyClient.ChangePlaylistName(ctx, playlistID, previous.name); err != nil {
+			return "", fmt.Errorf("failed to change playlist name: %w", err)
+		}
+	}
+	if current.description != previous.description {
+		if err := spotifyClient.ChangePlaylistDescription(ctx, playlistID, previous.description); err != nil {
+			return "", fmt.Errorf("failed to change playlist description: %w", err)
+		}
+	}
+	// As in update_playlist, collaboration has to be off before a playlist
+	// can be made public.
+	if current.collaborative != previous.collaborative {
+		if err := client.ChangePlaylistCollaborative(ctx, playlistID, previous.collaborative); err != nil {
+			return "", fmt.Errorf("failed to change collaborative setting: %w", err)
+		}
+	}
+	if current.public != previous.public && !previous.collaborative {
+		if err := spotifyClient.ChangePlaylistAccess(ctx, playlistID, previous.public); err != nil {
+			return "", fmt.Errorf("failed to change playlist visibility: %w", err)
+		}
+	}
+
+	state, err := playlistState(ctx, playlistID)
+	if err != nil {
+		return "", err
+	}
+	return state.SnapshotID, nil
+}
+
+// addable reports whether an item can be added to a playlist, which local
+// files and unavailable items can't.
+func addable(uri spotify.URI) bool {
+	return uri != "" && !strings.HasPrefix(string(uri), "spotify:local:")
+}
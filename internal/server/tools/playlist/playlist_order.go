@@ -0,0 +1,255 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+)
+
+// Ways of applying a new order to a playlist. Reordering keeps when and by
+// whom each track was added; replacing takes fewer requests but resets them,
+// so it is only used when asked for. Auto reorders.
+const (
+	orderMethodAuto    = "auto"
+	orderMethodReorder = "reorder"
+	orderMethodReplace = "replace"
+)
+
+// playlistMove moves the item at from in front of the item at insertBefore,
+// both positions in the playlist before the move.
+type playlistMove struct {
+	from         int
+	insertBefore int
+}
+
+// orderResult describes how a new order was applied.
+type orderResult struct {
+	method     string
+	moves      int
+	snapshotID string
+}
+
+// planMoves returns the fewest single-item moves that rearrange a playlist
+// into order, where order[i] is the current position of the item that should
+// end up at position i. Items on a longest run already in the right relative
+// order stay put; every other item is moved right behind the item that should
+// precede it.
+func planMoves(order []int) []playlistMove {
+	rank := make([]int, len(order))
+	for target, current := range order {
+		rank[current] = target
+	}
+	keep := longestIncreasing(rank)
+
+	current := make([]int, len(order))
+	for i := range current {
+		current[i] = i
+	}
+	indexOf := func(item int) int {
+		for i, value := range current {
+			if value == item {
+				return i
+			}
+		}
+		return -1
+	}
+
+	var moves []playlistMove
+	for target, item := range order {
+		if keep[item] {
+			continue
+		}
+
+		from := indexOf(item)
+		insertBefore := 0
+		if target > 0 {
+			insertBefore = indexOf(order[target-1]) + 1
+		}
+		if from == insertBefore {
+			continue
+		}
+		moves = append(moves, playlistMove{from: from, insertBefore: insertBefore})
+
+		current = append(current[:from], current[from+1:]...)
+		to := insertBefore
+		if insertBefore > from {
+			to--
+		}
+		current = append(current[:to], append([]int{item}, current[to:]...)...)
+	}
+
+	return moves
+}
+
+// longestIncreasing marks the positions of seq that form one of its longest
+// strictly increasing subsequences.
+func longestIncreasing(seq []int) []bool {
+	// tails[k] is the index in seq ending the best run of length k+1.
+	var tails []int
+	previous := make([]int, len(seq))
+	for i, value := range seq {
+		k := sort.Search(len(tails), func(k int) bool { return seq[tails[k]] >= value })
+		previous[i] = -1
+		if k > 0 {
+			previous[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	marked := make([]bool, len(seq))
+	if len(tails) == 0 {
+		return marked
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = previous[i] {
+		marked[i] = true
+	}
+	return marked
+}
+
+// canReplace reports whether every item can be added back to a playlist,
+// which local files and unavailable items can't.
+func canReplace(items []spotify.PlaylistItem) bool {
+	for _, item := range items {
+		if item.IsLocal || playlistItemURI(item) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// playlistItemURIs returns the URI of every item.
+func playlistItemURIs(items []spotify.PlaylistItem) []spotify.URI {
+	uris := make([]spotify.URI, len(items))
+	for i, item := range items {
+		uris[i] = playlistItemURI(item)
+	}
+	return uris
+}
+
+// applyPlaylistOrder rearranges a playlist holding uris into order, as
+// returned by planMoves. The reorder and auto methods move one track per
+// request, each pinned to the snapshot of the one before; replace rewrites the
+// whole playlist, which is only possible when replaceable.
+func applyPlaylistOrder(ctx context.Context, playlistID spotify.ID, uris []spotify.URI, replaceable bool, order []int, snapshotID, method string) (orderResult, error) {
+	moves := planMoves(order)
+	result := orderResult{method: method, snapshotID: snapshotID}
+
+	if method == orderMethodAuto {
+		result.method = orderMethodReorder
+	}
+
+	if len(moves) == 0 {
+		result.method = orderMethodReorder
+		return result, nil
+	}
+
+	if result.method == orderMethodReplace {
+		if !replaceable {
+			return result, fmt.Errorf("the playlist has local files or unavailable tracks, which can't be added back after replacing")
+		}
+
+		ordered := make([]spotify.URI, len(order))
+		for i, position := range order {
+			ordered[i] = uris[position]
+		}
+
+		newSnapshotID, err := replacePlaylistItems(ctx, playlistID, uris, ordered)
+		if err != nil {
+			return result, err
+		}
+		result.snapshotID = newSnapshotID
+		result.moves = len(moves)
+		return result, nil
+	}
+
+	for _, move := range moves {
+		newSnapshotID, err := client.AuthenticatedSpotifyClient.ReorderPlaylistTracks(ctx, playlistID, spotify.PlaylistReorderOptions{
+			RangeStart:   spotify.Numeric(move.from),
+			RangeLength:  1,
+			InsertBefore: spotify.Numeric(move.insertBefore),
+			SnapshotID:   result.snapshotID,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to reorder playlist tracks after %d of %d moves: %w", result.moves, len(moves), err)
+		}
+		result.snapshotID = newSnapshotID
+		result.moves++
+	}
+
+	return result, nil
+}
+
+// replacePlaylistItems replaces the items of a playlist holding previous with
+// uris. Spotify replaces at most 100 items per request, so the rest are
+// appended in batches, and the playlist is read back to check the result. If
+// a request fails or the playlist doesn't hold uris afterwards, previous is
+// written back the same way and an error is returned.
+func replacePlaylistItems(ctx context.Context, playlistID spotify.ID, previous, uris []spotify.URI) (string, error) {
+	snapshotID, err := writePlaylistItems(ctx, playlistID, uris)
+	if err == nil {
+		snapshotID, err = checkPlaylistItems(ctx, playlistID, uris, snapshotID)
+	}
+	if err == nil {
+		return snapshotID, nil
+	}
+
+	if _, rollbackErr := writePlaylistItems(ctx, playlistID, previous); rollbackErr != nil {
+		return "", fmt.Errorf("failed to replace playlist items: %w; putting the previous %d items back also failed: %v", err, len(previous), rollbackErr)
+	}
+	if _, rollbackErr := checkPlaylistItems(ctx, playlistID, previous, ""); rollbackErr != nil {
+		return "", fmt.Errorf("failed to replace playlist items: %w; the previous %d items were written back but %v", err, len(previous), rollbackErr)
+	}
+
+	return "", fmt.Errorf("failed to replace playlist items, so the previous %d items were put back: %w", len(previous), err)
+}
+
+// writePlaylistItems replaces a playlist's items with uris: the first 100 in
+// one request, the rest appended in batches.
+func writePlaylistItems(ctx context.Context, playlistID spotify.ID, uris []spotify.URI) (string, error) {
+	first := uris[:min(playlistBatchSize, len(uris))]
+	snapshotID, err := client.AuthenticatedSpotifyClient.ReplacePlaylistItems(ctx, playlistID, first...)
+	if err != nil {
+		return "", err
+	}
+
+	if len(uris) > len(first) {
+		newSnapshotID, added, err := addItemsInBatches(ctx, playlistID, uris[len(first):], -1)
+		if err != nil {
+			return "", fmt.Errorf("replaced the first %d items but only re-added %d of the remaining %d: %w", len(first), added, len(uris)-len(first), err)
+		}
+		snapshotID = newSnapshotID
+	}
+
+	return snapshotID, nil
+}
+
+// checkPlaylistItems reads a playlist back and reports an error unless it
+// holds exactly uris. It returns the playlist's snapshot ID, or snapshotID
+// when Spotify's own is unknown.
+func checkPlaylistItems(ctx context.Context, playlistID spotify.ID, uris []spotify.URI, snapshotID string) (string, error) {
+	playlist, items, err := loadPlaylist(ctx, client.AuthenticatedSpotifyClient, playlistID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(items) != len(uris) {
+		return "", fmt.Errorf("the playlist has %d items instead of %d", len(items), len(uris))
+	}
+	for i, item := range items {
+		if playlistItemURI(item) != uris[i] {
+			return "", fmt.Errorf("item %d is %s instead of %s", i, playlistItemURI(item), uris[i])
+		}
+	}
+
+	if playlist.SnapshotID != "" {
+		snapshotID = playlist.SnapshotID
+	}
+	return snapshotID, nil
+}
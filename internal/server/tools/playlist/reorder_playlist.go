@@ -0,0 +1,135 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+)
+
+func reorderPlaylistTracksTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"reorder_playlist_tracks",
+		mcp.WithDescription("Move a range of tracks in a playlist to another position"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+		mcp.WithNumber("Range Start",
+			mcp.Required(),
+			mcp.Description("Zero-based position of the first track to move"),
+		),
+		mcp.WithNumber("Range Length",
+			mcp.Description("Number of tracks to move (default: 1)"),
+		),
+		mcp.WithNumber("Insert Before",
+			mcp.Required(),
+			mcp.Description("Zero-based position, in the playlist before the move, to move the tracks in front of. Use the playlist length to move them to the end"),
+		),
+		mcp.WithString("Snapshot ID",
+			mcp.Description("Snapshot ID the positions refer to. Spotify applies the move to that version of the playlist if it has changed since"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  reorderPlaylistTracksBehaviour,
+	}
+}
+
+func reorderPlaylistTracksBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	rangeStart, err := tools.GetIntParamFromRequest(request, "Range Start")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get range start: %w", err)
+	}
+
+	insertBefore, err := tools.GetIntParamFromRequest(request, "Insert Before")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get insert before: %w", err)
+	}
+
+	rangeLength, err := tools.GetIntParamFromRequest(request, "Range Length")
+	if err != nil {
+		rangeLength = 1
+	}
+
+	if rangeStart < 0 || insertBefore < 0 || rangeLength < 1 {
+		return mcp.NewToolResultText("Range Start and Insert Before must be zero or more, and Range Length at least 1."), nil
+	}
+
+	if insertBefore >= rangeStart && insertBefore <= rangeStart+rangeLength {
+		return mcp.NewToolResultText("Insert Before is inside or right after the range, so the tracks would stay where they are."), nil
+	}
+
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+	}
+
+	snapshotID, _ := tools.GetParamFromRequest(request, "Snapshot ID")
+	snapshotID = strings.TrimSpace(snapshotID)
+
+	state, err := playlistState(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	undoSummary := fmt.Sprintf("Move the track(s) back to position %d", rangeStart)
+	undo := undoReorder(playlistID, rangeStart, rangeLength, insertBefore)
+	if snapshotID == "" {
+		// Pin the move to the version just read, which the undo is based on.
+		snapshotID = state.SnapshotID
+	} else if snapshotID != state.SnapshotID {
+		undoSummary = "Put the tracks back in the order they had before the move"
+		undo, err = undoToCurrent(ctx, playlistID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	newSnapshotID, err := client.AuthenticatedSpotifyClient.ReorderPlaylistTracks(ctx, playlistID, spotify.PlaylistReorderOptions{
+		RangeStart:   spotify.Numeric(rangeStart),
+		RangeLength:  spotify.Numeric(rangeLength),
+		InsertBefore: spotify.Numeric(insertBefore),
+		SnapshotID:   snapshotID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reorder playlist tracks: %w", err)
+	}
+
+	// Positions after the move, for a follow-up call.
+	newStart := insertBefore
+	if insertBefore > rangeStart {
+		newStart = insertBefore - rangeLength
+	}
+
+	recordChange(journalEntry{
+		tool:           "reorder_playlist_tracks",
+		playlistID:     playlistID,
+		playlistName:   state.Name,
+		summary:        fmt.Sprintf("Moved %d track(s) from position %d to %d", rangeLength, rangeStart, newStart),
+		snapshotBefore: state.SnapshotID,
+		snapshotAfter:  newSnapshotID,
+		undoSummary:    undoSummary,
+		undo:           undo,
+	})
+
+	response := fmt.Sprintf("Successfully moved %d tracks from position %d to position %d!\n", rangeLength, rangeStart, newStart)
+	response += fmt.Sprintf("Playlist ID: %s\n", playlistID)
+	response += fmt.Sprintf("New snapshot ID: %s\n", newSnapshotID)
+
+	return mcp.NewToolResultText(response), nil
+}
@@ -0,0 +1,526 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+	"spotify-mcp/internal/snapshot"
+)
+
+// liveSnapshot names the playlist as it is now, wherever a snapshot ID is
+// expected.
+const liveSnapshot = "live"
+
+func snapshotPlaylistTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"snapshot_playlist",
+		mcp.WithDescription("Save the playlist's name, description and ordered tracks locally, with Spotify's snapshot ID, so it can be compared with diff_playlist and rolled back with restore_playlist"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+		mcp.WithString("note",
+			mcp.Description("A note to remember the snapshot by"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  snapshotPlaylistBehaviour,
+	}
+}
+
+func snapshotPlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	note, _ := tools.GetParamFromRequest(request, "note")
+
+	spotifyClient := readClient()
+	if spotifyClient == nil {
+		return mcp.NewToolResultError("Spotify client not initialized. Please use the spotify_login tool first."), nil
+	}
+
+	playlist, items, err := loadPlaylist(ctx, spotifyClient, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := playlistSnapshot(playlist, items, strings.TrimSpace(note))
+	if err := snapshot.Save(&saved); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	response := fmt.Sprintf("Saved a snapshot of %s with %d tracks.\n", playlist.Name, len(items))
+	response += fmt.Sprintf("Snapshot: %s\n", saved.ID)
+	response += fmt.Sprintf("Playlist ID: %s\n", playlist.ID)
+	response += fmt.Sprintf("Spotify snapshot ID: %s\n", playlist.SnapshotID)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func listPlaylistSnapshotsTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"list_playlist_snapshots",
+		mcp.WithDescription("List the local snapshots of a playlist, oldest first"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  listPlaylistSnapshotsBehaviour,
+	}
+}
+
+func listPlaylistSnapshotsBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	snapshots, err := snapshot.List(string(playlistID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	if len(snapshots) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No snapshots of playlist %s. Take one with snapshot_playlist.", playlistID)), nil
+	}
+
+	response := fmt.Sprintf("Snapshots of %s (%d):\n\n", snapshots[len(snapshots)-1].Name, len(snapshots))
+	for _, saved := range snapshots {
+		response += fmt.Sprintf("- %s: %s, %d tracks, taken %s", saved.ID, saved.Name, len(saved.Items), saved.CreatedAt.Local().Format("2006-01-02 15:04"))
+		if saved.Note != "" {
+			response += fmt.Sprintf(" (%s)", saved.Note)
+		}
+		response += "\n"
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func diffPlaylistTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"diff_playlist",
+		mcp.WithDescription("Show the tracks added, removed and moved between two snapshots of a playlist, or between a snapshot and the playlist as it is now"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+		mcp.WithString("from",
+			mcp.Description("Snapshot to compare from, as listed by list_playlist_snapshots (default: the latest snapshot)"),
+		),
+		mcp.WithString("to",
+			mcp.Description("Snapshot to compare to, or \"live\" for the playlist as it is now (default: live)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  diffPlaylistBehaviour,
+	}
+}
+
+func diffPlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	fromID, _ := tools.GetParamFromRequest(request, "from")
+	toID, _ := tools.GetParamFromRequest(request, "to")
+	fromID = strings.TrimSpace(fromID)
+	toID = strings.TrimSpace(toID)
+	if toID == "" {
+		toID = liveSnapshot
+	}
+
+	from, err := loadSnapshot(ctx, playlistID, fromID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not read the from snapshot: %v", err)), nil
+	}
+
+	to, err := loadSnapshot(ctx, playlistID, toID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not read the to snapshot: %v", err)), nil
+	}
+
+	diff := diffItems(from.Items, to.Items)
+
+	response := fmt.Sprintf("Changes to %s from %s to %s:\n", to.Name, snapshotLabel(from), snapshotLabel(to))
+	if from.SpotifySnapshotID != "" && from.SpotifySnapshotID == to.SpotifySnapshotID {
+		response += "Both have the same Spotify snapshot ID.\n"
+	}
+	response += formatPlaylistDiff(from, to, diff)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func restorePlaylistTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"restore_playlist",
+		mcp.WithDescription("Rewrite a playlist to the tracks, order, name, description, visibility and collaborative setting of a saved snapshot. The current state is snapshotted first, so a restore can itself be rolled back"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+		mcp.WithString("snapshot",
+			mcp.Description("Snapshot to restore, as listed by list_playlist_snapshots (default: the latest snapshot)"),
+		),
+		mcp.WithString("method",
+			mcp.Description("auto and reorder remove, add and move only the tracks that differ, keeping the added dates of the rest; replace rewrites the whole playlist, resetting them (default: auto)"),
+			mcp.Enum(orderMethodAuto, orderMethodReorder, orderMethodReplace),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only show what would change (default: false)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  restorePlaylistBehaviour,
+	}
+}
+
+func restorePlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	snapshotID, _ := tools.GetParamFromRequest(request, "snapshot")
+	snapshotID = strings.TrimSpace(snapshotID)
+	if snapshotID == liveSnapshot {
+		return mcp.NewToolResultError("Choose a saved snapshot to restore, not the live playlist."), nil
+	}
+
+	method, err := orderMethodFromRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	dryRun, _ := tools.GetBoolParamFromRequest(request, "dry_run")
+
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+	}
+
+	target, err := loadSnapshot(ctx, playlistID, snapshotID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not read the snapshot: %v", err)), nil
+	}
+
+	playlist, items, err := loadPlaylist(ctx, client.AuthenticatedSpotifyClient, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	live := playlistSnapshot(playlist, items, "")
+	diff := diffItems(live.Items, target.Items)
+	metadataChanged := live.Name != target.Name || live.Description != target.Description ||
+		live.Public != target.Public || live.Collaborative != target.Collaborative
+
+	if len(diff.added) == 0 && len(diff.removed) == 0 && len(diff.moved) == 0 && !metadataChanged {
+		response := fmt.Sprintf("%s already matches snapshot %s; nothing to change.\n", playlist.Name, target.ID)
+		response += fmt.Sprintf("Snapshot ID: %s\n", playlist.SnapshotID)
+		return mcp.NewToolResultText(response), nil
+	}
+
+	var unavailable int
+	for _, change := range diff.added {
+		if !restorable(change.item) {
+			unavailable++
+		}
+	}
+
+	if method == orderMethodReplace && (unavailable > 0 || !canReplace(items)) {
+		return mcp.NewToolResultError("The playlist or the snapshot has local files or unavailable tracks, which can't be added back after replacing. Use the auto method instead."), nil
+	}
+
+	if dryRun {
+		response := fmt.Sprintf("Dry run: restoring %s to snapshot %s.\n", playlist.Name, target.ID)
+		response += formatPlaylistDiff(live, target, diff)
+		if unavailable > 0 {
+			response += fmt.Sprintf("\n%d local file(s) or unavailable track(s) in the snapshot can't be added back.\n", unavailable)
+		}
+		return mcp.NewToolResultText(response), nil
+	}
+
+	backup := live
+	backup.Note = "Before restoring " + target.ID
+	if err := snapshot.Save(&backup); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	var newSnapshotID string
+	var replaced bool
+	var moves int
+	if method == orderMethodReplace {
+		uris := make([]spotify.URI, len(target.Items))
+		for i, item := range target.Items {
+			uris[i] = spotify.URI(item.URI)
+		}
+		newSnapshotID, err = replacePlaylistItems(ctx, playlistID, playlistItemURIs(items), uris)
+		if err != nil {
+			return nil, fmt.Errorf("%w (the state before the restore was saved as snapshot %s)", err, backup.ID)
+		}
+		replaced = true
+	} else {
+		result, err := restoreItems(ctx, playlistID, items, target.Items, diff, playlist.SnapshotID, method)
+		if err != nil {
+			return nil, fmt.Errorf("%w (the state before the restore was saved as snapshot %s)", err, backup.ID)
+		}
+		newSnapshotID = result.snapshotID
+		moves = result.moves
+	}
+
+	if metadataChanged {
+		newSnapshotID, err = restoreDetails(ctx, playlistID, snapshotDetails(live), snapshotDetails(target))
+		if err != nil {
+			return nil, fmt.Errorf("%w (the state before the restore was saved as snapshot %s)", err, backup.ID)
+		}
+	}
+
+	recordChange(journalEntry{
+		tool:           "restore_playlist",
+		playlistID:     playlistID,
+		playlistName:   target.Name,
+		summary:        fmt.Sprintf("Restored snapshot %s", target.ID),
+		snapshotBefore: playlist.SnapshotID,
+		snapshotAfter:  newSnapshotID,
+		undoSummary:    fmt.Sprintf("Restore snapshot %s, taken just before", backup.ID),
+		undo:           undoRestore(playlistID, backup),
+	})
+
+	response := fmt.Sprintf("Successfully restored %s to snapshot %s!\n", target.Name, target.ID)
+	if replaced {
+		response += fmt.Sprintf("Replaced the playlist's tracks with the snapshot's %d (added dates were reset).\n", len(target.Items))
+	} else {
+		response += fmt.Sprintf("Removed %d, added %d and moved %d track(s).\n", len(diff.removed), len(diff.added)-unavailable, moves)
+	}
+	if unavailable > 0 {
+		response += fmt.Sprintf("%d local file(s) or unavailable track(s) in the snapshot couldn't be added back.\n", unavailable)
+	}
+	if metadataChanged {
+		response += "Restored the name, description, visibility and collaborative setting.\n"
+	}
+	response += fmt.Sprintf("The previous state was saved as snapshot %s.\n", backup.ID)
+	response += fmt.Sprintf("Playlist ID: %s\n", playlistID)
+	if newSnapshotID != "" {
+		response += fmt.Sprintf("New snapshot ID: %s\n", newSnapshotID)
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// restoreItems turns a playlist holding items into target with the fewest
+// changes: it removes the items diff reports as removed, appends the added
+// ones that can be added back and then moves everything into the target
+// order. Items that can't be removed or placed end up at the end.
+func restoreItems(ctx context.Context, playlistID spotify.ID, items []spotify.PlaylistItem, target []snapshot.Item, diff playlistDiff, snapshotID, method string) (orderResult, error) {
+	removing := map[int]bool{}
+	var positions []int
+	for _, change := range diff.removed {
+		if playlistItemURI(items[change.position]) == "" {
+			continue
+		}
+		removing[change.position] = true
+		positions = append(positions, change.position)
+	}
+
+	if len(positions) > 0 {
+		newSnapshotID, removed, err := removePositionsInBatches(ctx, playlistID, items, positions, snapshotID)
+		if err != nil {
+			return orderResult{}, fmt.Errorf("failed to remove tracks after removing %d of %d: %w", removed, len(positions), err)
+		}
+		snapshotID = newSnapshotID
+	}
+
+	var current []spotify.URI
+	replaceable := true
+	for i, item := range items {
+		if removing[i] {
+			continue
+		}
+		current = append(current, playlistItemURI(item))
+		if item.IsLocal || playlistItemURI(item) == "" {
+			replaceable = false
+		}
+	}
+
+	var adding []spotify.URI
+	for _, change := range diff.added {
+		if restorable(change.item) {
+			adding = append(adding, spotify.URI(change.item.URI))
+		}
+	}
+
+	if len(adding) > 0 {
+		newSnapshotID, added, err := addItemsInBatches(ctx, playlistID, adding, -1)
+		if err != nil {
+			return orderResult{}, fmt.Errorf("failed to add tracks after adding %d of %d: %w", added, len(adding), err)
+		}
+		snapshotID = newSnapshotID
+		current = append(current, adding...)
+	}
+
+	// Items with the same URI are interchangeable, so the k-th copy in the
+	// target takes the k-th copy left in the playlist.
+	available := map[spotify.URI][]int{}
+	for i, uri := range current {
+		available[uri] = append(available[uri], i)
+	}
+
+	placed := make([]bool, len(current))
+	order := make([]int, 0, len(current))
+	for _, item := range target {
+		uri := spotify.URI(item.URI)
+		if len(available[uri]) == 0 {
+			continue
+		}
+		order = append(order, available[uri][0])
+		placed[available[uri][0]] = true
+		available[uri] = available[uri][1:]
+	}
+	for i := range current {
+		if !placed[i] {
+			order = append(order, i)
+		}
+	}
+
+	return applyPlaylistOrder(ctx, playlistID, current, replaceable, order, snapshotID, method)
+}
+
+// snapshotDetails returns the details of a snapshot that update_playlist
+// changes.
+func snapshotDetails(saved snapshot.Snapshot) playlistDetails {
+	return playlistDetails{
+		name:          saved.Name,
+		description:   saved.Description,
+		public:        saved.Public,
+		collaborative: saved.Collaborative,
+	}
+}
+
+// restorable reports whether an item can be added to a playlist again.
+func restorable(item snapshot.Item) bool {
+	return item.URI != "" && !item.IsLocal
+}
+
+// loadSnapshot reads a saved snapshot, the latest when id is empty, or takes
+// one of the live playlist when id is "live".
+func loadSnapshot(ctx context.Context, playlistID spotify.ID, id string) (snapshot.Snapshot, error) {
+	switch id {
+	case "":
+		return snapshot.Latest(string(playlistID))
+	case liveSnapshot:
+		spotifyClient := readClient()
+		if spotifyClient == nil {
+			return snapshot.Snapshot{}, fmt.Errorf("Spotify client not initialized, please use the spotify_login tool first")
+		}
+		playlist, items, err := loadPlaylist(ctx, spotifyClient, playlistID)
+		if err != nil {
+			return snapshot.Snapshot{}, err
+		}
+		return playlistSnapshot(playlist, items, ""), nil
+	default:
+		return snapshot.Load(string(playlistID), id)
+	}
+}
+
+// playlistSnapshot captures a playlist and its items, ready to be saved.
+func playlistSnapshot(playlist *spotify.FullPlaylist, items []spotify.PlaylistItem, note string) snapshot.Snapshot {
+	owner := playlist.Owner.DisplayName
+	if owner == "" {
+		owner = playlist.Owner.ID
+	}
+
+	saved := snapshot.Snapshot{
+		PlaylistID:        string(playlist.ID),
+		SpotifySnapshotID: playlist.SnapshotID,
+		Note:              note,
+		Name:              playlist.Name,
+		Description:       playlist.Description,
+		Owner:             owner,
+		Public:            playlist.IsPublic,
+		Collaborative:     playlist.Collaborative,
+		Items:             snapshotItems(items),
+	}
+
+	return saved
+}
+
+// snapshotItems converts playlist items for a snapshot.
+func snapshotItems(items []spotify.PlaylistItem) []snapshot.Item {
+	converted := make([]snapshot.Item, len(items))
+	for i, item := range items {
+		entry := snapshot.Item{
+			URI:     string(playlistItemURI(item)),
+			AddedAt: item.AddedAt,
+			AddedBy: item.AddedBy.ID,
+			IsLocal: item.IsLocal,
+		}
+
+		switch {
+		case item.Track.Track != nil:
+			track := item.Track.Track
+			entry.Title = track.Name
+			entry.Artists = trackArtistNames(track)
+			entry.Album = track.Album.Name
+			entry.DurationMs = int(track.Duration)
+		case item.Track.Episode != nil:
+			entry.Title = item.Track.Episode.Name
+			entry.DurationMs = int(item.Track.Episode.Duration_ms)
+		default:
+			entry.Title = "Unavailable item"
+		}
+
+		converted[i] = entry
+	}
+
+	return converted
+}
+
+func snapshotLabel(saved snapshot.Snapshot) string {
+	if saved.ID == "" {
+		return "the live playlist"
+	}
+	return "snapshot " + saved.ID
+}
+
+func snapshotItemName(item snapshot.Item) string {
+	if len(item.Artists) == 0 {
+		return item.Title
+	}
+	return fmt.Sprintf("%s by %s", item.Title, strings.Join(item.Artists, ", "))
+}
@@ -0,0 +1,559 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/matching"
+	"spotify-mcp/internal/server/tools"
+)
+
+// audioFeaturesBatchSize is the most tracks GetAudioFeatures takes at once.
+const audioFeaturesBatchSize = 100
+
+// previewLength is how many tracks a dry run lists.
+const previewLength = 50
+
+var sortKeyNames = []string{"title", "artist", "album", "release_date", "added_at", "duration", "popularity", "tempo"}
+
+// sortKey is one key of a multi-key sort.
+type sortKey struct {
+	name       string
+	descending bool
+}
+
+// sortValue is an item's value for one key. Items missing a value sort last
+// in either direction.
+type sortValue struct {
+	text    string
+	number  float64
+	missing bool
+}
+
+func sortPlaylistTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"sort_playlist",
+		mcp.WithDescription("Sort the tracks of a playlist in place. Sorting is stable, so tracks that compare equal on every key keep their order"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+		mcp.WithString("by",
+			mcp.Required(),
+			mcp.Description("Comma-separated sort keys, each optionally followed by :asc or :desc, e.g. \"artist,release_date:desc\". Keys: title, artist, album, release_date, added_at, duration, popularity, tempo"),
+		),
+		mcp.WithString("direction",
+			mcp.Description("Direction for keys without one: \"asc\" or \"desc\" (default: asc)"),
+			mcp.Enum("asc", "desc"),
+		),
+		mcp.WithString("method",
+			mcp.Description("\"reorder\" moves tracks one at a time and keeps their added dates, \"replace\" rewrites the playlist in a few requests but resets them, \"auto\" reorders (default: auto)"),
+			mcp.Enum(orderMethodAuto, orderMethodReorder, orderMethodReplace),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Show the new order without changing the playlist (default: false)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  sortPlaylistBehaviour,
+	}
+}
+
+func sortPlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	byParam, err := tools.GetParamFromRequest(request, "by")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get by parameter: %w", err)
+	}
+
+	direction, _ := tools.GetParamFromRequest(request, "direction")
+	keys, err := parseSortKeys(byParam, direction)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid sort: %v", err)), nil
+	}
+
+	method, err := orderMethodFromRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	dryRun, _ := tools.GetBoolParamFromRequest(request, "dry_run")
+
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+	}
+
+	playlist, items, err := loadPlaylist(ctx, client.AuthenticatedSpotifyClient, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := sortValues(ctx, items, keys)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not sort the playlist: %v", err)), nil
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := values[order[i]], values[order[j]]
+		for k, key := range keys {
+			if c := compareSortValues(a[k], b[k], key.descending); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	action := "Sorting by " + formatSortKeys(keys)
+	return applyOrderResponse(ctx, "sort_playlist", playlist, items, order, method, dryRun, action, "")
+}
+
+func reshufflePlaylistTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"reshuffle_playlist",
+		mcp.WithDescription("Shuffle the tracks of a playlist in place, unlike the shuffle tool which only changes playback. By default no artist plays twice in a row"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+		mcp.WithBoolean("spread_artists",
+			mcp.Description("Avoid the same artist on consecutive tracks (default: true)"),
+		),
+		mcp.WithBoolean("spread_albums",
+			mcp.Description("Avoid the same album on consecutive tracks (default: false)"),
+		),
+		mcp.WithNumber("seed",
+			mcp.Description("Random seed, to repeat a shuffle (default: random)"),
+		),
+		mcp.WithString("method",
+			mcp.Description("\"reorder\" moves tracks one at a time and keeps their added dates, \"replace\" rewrites the playlist in a few requests but resets them, \"auto\" reorders (default: auto)"),
+			mcp.Enum(orderMethodAuto, orderMethodReorder, orderMethodReplace),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Show the new order without changing the playlist (default: false)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  reshufflePlaylistBehaviour,
+	}
+}
+
+func reshufflePlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	spreadArtists, err := tools.GetBoolParamFromRequest(request, "spread_artists")
+	if err != nil {
+		spreadArtists = true
+	}
+	spreadAlbums, _ := tools.GetBoolParamFromRequest(request, "spread_albums")
+
+	seed, err := tools.GetIntParamFromRequest(request, "seed")
+	if err != nil {
+		seed = int(time.Now().UnixNano() % 1_000_000_000)
+	}
+
+	method, err := orderMethodFromRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	dryRun, _ := tools.GetBoolParamFromRequest(request, "dry_run")
+
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+	}
+
+	playlist, items, err := loadPlaylist(ctx, client.AuthenticatedSpotifyClient, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, conflicts := shuffleOrder(items, spreadArtists, spreadAlbums, rand.New(rand.NewSource(int64(seed))))
+
+	action := fmt.Sprintf("Reshuffling with seed %d", seed)
+	var note string
+	if conflicts > 0 {
+		note = fmt.Sprintf("%d pair(s) of consecutive tracks share an artist or album; there were too many of them to spread out.\n", conflicts)
+	}
+
+	return applyOrderResponse(ctx, "reshuffle_playlist", playlist, items, order, method, dryRun, action, note)
+}
+
+func orderMethodFromRequest(request mcp.CallToolRequest) (string, error) {
+	method, _ := tools.GetParamFromRequest(request, "method")
+	method = strings.ToLower(strings.TrimSpace(method))
+	switch method {
+	case "":
+		return orderMethodAuto, nil
+	case orderMethodAuto, orderMethodReorder, orderMethodReplace:
+		return method, nil
+	default:
+		return "", fmt.Errorf("method must be %q, %q or %q", orderMethodAuto, orderMethodReorder, orderMethodReplace)
+	}
+}
+
+// applyOrderResponse applies order to the playlist, or previews it on a dry
+// run, and describes the outcome. The action names what produced the order,
+// and the note is added to the response as is. Applied orders are recorded in
+// the journal under tool.
+func applyOrderResponse(ctx context.Context, tool string, playlist *spotify.FullPlaylist, items []spotify.PlaylistItem, order []int, method string, dryRun bool, action, note string) (*mcp.CallToolResult, error) {
+	moves := planMoves(order)
+
+	if len(moves) == 0 {
+		response := fmt.Sprintf("%s is already in that order (%d tracks); nothing to change.\n", playlist.Name, len(items))
+		response += fmt.Sprintf("Snapshot ID: %s\n", playlist.SnapshotID)
+		return mcp.NewToolResultText(response), nil
+	}
+
+	if method == orderMethodReplace && !canReplace(items) {
+		return mcp.NewToolResultError("The playlist has local files or unavailable tracks, which can't be added back after replacing. Use the reorder method instead."), nil
+	}
+
+	if dryRun {
+		response := fmt.Sprintf("Dry run: %s moves %d of %d tracks in %s.\n", action, len(moves), len(items), playlist.Name)
+		response += note
+		response += "\nNew order:\n"
+		response += formatOrderPreview(items, order)
+		return mcp.NewToolResultText(response), nil
+	}
+
+	result, err := applyPlaylistOrder(ctx, playlist.ID, playlistItemURIs(items), canReplace(items), order, playlist.SnapshotID, method)
+	if err != nil {
+		// Record the moves that were made, so a partly applied order can
+		// be undone by going back to the playlist as it was read.
+		if result.moves > 0 {
+			recordChange(journalEntry{
+				tool:           tool,
+				playlistID:     playlist.ID,
+				playlistName:   playlist.Name,
+				summary:        fmt.Sprintf("%s moved %d of %d tracks before failing", action, result.moves, len(moves)),
+				snapshotBefore: playlist.SnapshotID,
+				snapshotAfter:  result.snapshotID,
+				undoSummary:    "Put the tracks back in the order they had before",
+				undo:           undoRestore(playlist.ID, playlistSnapshot(playlist, items, "")),
+			})
+		}
+		return nil, err
+	}
+
+	recordChange(journalEntry{
+		tool:           tool,
+		playlistID:     playlist.ID,
+		playlistName:   playlist.Name,
+		summary:        fmt.Sprintf("%s moved %d of %d tracks", action, len(moves), len(items)),
+		snapshotBefore: playlist.SnapshotID,
+		snapshotAfter:  result.snapshotID,
+		undoSummary:    "Put the tracks back in their previous order",
+		undo:           undoOrder(playlist.ID, order),
+	})
+
+	response := fmt.Sprintf("Successfully reordered %s!\n", playlist.Name)
+	response += note
+	if result.method == orderMethodReplace {
+		response += fmt.Sprintf("%s replaced the playlist's %d tracks in the new order (added dates were reset).\n", action, len(items))
+	} else {
+		response += fmt.Sprintf("%s moved %d of %d tracks, one reorder request each.\n", action, result.moves, len(items))
+	}
+	response += fmt.Sprintf("Playlist ID: %s\n", playlist.ID)
+	response += fmt.Sprintf("New snapshot ID: %s\n", result.snapshotID)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func formatOrderPreview(items []spotify.PlaylistItem, order []int) string {
+	var response string
+	for i, position := range order {
+		if i == previewLength {
+			response += fmt.Sprintf("... and %d more\n", len(order)-previewLength)
+			break
+		}
+		response += fmt.Sprintf("%d. %s (was %d)\n", i, playlistItemName(items[position]), position)
+	}
+	return response
+}
+
+// parseSortKeys parses "key[:asc|:desc],..." with direction as the default.
+func parseSortKeys(value, direction string) ([]sortKey, error) {
+	direction = strings.ToLower(strings.TrimSpace(direction))
+	if direction != "" && direction != "asc" && direction != "desc" {
+		return nil, fmt.Errorf("direction must be \"asc\" or \"desc\"")
+	}
+
+	var keys []sortKey
+	for _, part := range tools.SplitCommaSeparated(value) {
+		name, keyDirection, _ := strings.Cut(strings.ToLower(part), ":")
+		name = strings.ReplaceAll(strings.TrimSpace(name), " ", "_")
+		keyDirection = strings.TrimSpace(keyDirection)
+		if keyDirection == "" {
+			keyDirection = direction
+		}
+
+		known := false
+		for _, keyName := range sortKeyNames {
+			if name == keyName {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown key %q, use one of %s", name, strings.Join(sortKeyNames, ", "))
+		}
+		if keyDirection != "" && keyDirection != "asc" && keyDirection != "desc" {
+			return nil, fmt.Errorf("unknown direction %q for %s", keyDirection, name)
+		}
+
+		keys = append(keys, sortKey{name: name, descending: keyDirection == "desc"})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no sort keys given")
+	}
+
+	return keys, nil
+}
+
+func formatSortKeys(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key.name
+		if key.descending {
+			parts[i] += " (descending)"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortValues looks up every item's value for each key, fetching tempos from
+// Spotify's audio features when sorting by tempo.
+func sortValues(ctx context.Context, items []spotify.PlaylistItem, keys []sortKey) ([][]sortValue, error) {
+	var tempos map[spotify.ID]float64
+	for _, key := range keys {
+		if key.name == "tempo" {
+			var err error
+			tempos, err = trackTempos(ctx, items)
+			if err != nil {
+				return nil, fmt.Errorf("tempos aren't available: %w", err)
+			}
+			break
+		}
+	}
+
+	values := make([][]sortValue, len(items))
+	for i, item := range items {
+		values[i] = make([]sortValue, len(keys))
+		for k, key := range keys {
+			values[i][k] = itemSortValue(item, key.name, tempos)
+		}
+	}
+
+	return values, nil
+}
+
+func itemSortValue(item spotify.PlaylistItem, key string, tempos map[spotify.ID]float64) sortValue {
+	if key == "added_at" {
+		return sortValue{text: item.AddedAt, missing: item.AddedAt == ""}
+	}
+
+	track := item.Track.Track
+	if track == nil {
+		if episode := item.Track.Episode; episode != nil {
+			switch key {
+			case "title":
+				return sortValue{text: matching.Normalize(episode.Name)}
+			case "release_date":
+				return sortValue{text: episode.ReleaseDate, missing: episode.ReleaseDate == ""}
+			case "duration":
+				return sortValue{number: float64(episode.Duration_ms)}
+			}
+		}
+		return sortValue{missing: true}
+	}
+
+	switch key {
+	case "title":
+		return sortValue{text: matching.Normalize(track.Name)}
+	case "artist":
+		if len(track.Artists) == 0 {
+			return sortValue{missing: true}
+		}
+		return sortValue{text: matching.Normalize(track.Artists[0].Name)}
+	case "album":
+		return sortValue{text: matching.Normalize(track.Album.Name), missing: track.Album.Name == ""}
+	case "release_date":
+		return sortValue{text: track.Album.ReleaseDate, missing: track.Album.ReleaseDate == ""}
+	case "duration":
+		return sortValue{number: float64(track.Duration)}
+	case "popularity":
+		return sortValue{number: float64(track.Popularity), missing: item.IsLocal}
+	case "tempo":
+		tempo, ok := tempos[track.ID]
+		return sortValue{number: tempo, missing: !ok}
+	default:
+		return sortValue{missing: true}
+	}
+}
+
+// compareSortValues orders a before b (-1), after it (1) or neither (0).
+// Missing values go last whichever the direction.
+func compareSortValues(a, b sortValue, descending bool) int {
+	if a.missing || b.missing {
+		switch {
+		case a.missing && b.missing:
+			return 0
+		case a.missing:
+			return 1
+		default:
+			return -1
+		}
+	}
+
+	c := strings.Compare(a.text, b.text)
+	if c == 0 {
+		switch {
+		case a.number < b.number:
+			c = -1
+		case a.number > b.number:
+			c = 1
+		}
+	}
+
+	if descending {
+		return -c
+	}
+	return c
+}
+
+// trackTempos fetches the tempo of every track in items.
+func trackTempos(ctx context.Context, items []spotify.PlaylistItem) (map[spotify.ID]float64, error) {
+	var ids []spotify.ID
+	seen := map[spotify.ID]bool{}
+	for _, item := range items {
+		if track := item.Track.Track; track != nil && track.ID != "" && !seen[track.ID] {
+			seen[track.ID] = true
+			ids = append(ids, track.ID)
+		}
+	}
+
+	tempos := map[spotify.ID]float64{}
+	for start := 0; start < len(ids); start += audioFeaturesBatchSize {
+		features, err := client.AuthenticatedSpotifyClient.GetAudioFeatures(ctx, ids[start:min(start+audioFeaturesBatchSize, len(ids))]...)
+		if err != nil {
+			return nil, err
+		}
+		for _, feature := range features {
+			if feature != nil && feature.Tempo > 0 {
+				tempos[feature.ID] = float64(feature.Tempo)
+			}
+		}
+	}
+
+	return tempos, nil
+}
+
+// shuffleOrder returns a random order for items in which, as far as possible,
+// consecutive tracks don't share their main artist or album, and how many
+// consecutive pairs still do. At each step it takes the artist with the most
+// tracks left when that artist would otherwise run out of room to be spread.
+func shuffleOrder(items []spotify.PlaylistItem, spreadArtists, spreadAlbums bool, random *rand.Rand) ([]int, int) {
+	remaining := random.Perm(len(items))
+
+	artistKey := func(position int) string {
+		track := items[position].Track.Track
+		if !spreadArtists || track == nil || len(track.Artists) == 0 {
+			return ""
+		}
+		if track.Artists[0].ID != "" {
+			return string(track.Artists[0].ID)
+		}
+		return matching.Normalize(track.Artists[0].Name)
+	}
+	albumKey := func(position int) string {
+		track := items[position].Track.Track
+		if !spreadAlbums || track == nil || track.Album.Name == "" {
+			return ""
+		}
+		if track.Album.ID != "" {
+			return string(track.Album.ID)
+		}
+		return matching.Normalize(track.Album.Name)
+	}
+	conflict := func(a, b int) bool {
+		if key := artistKey(a); key != "" && key == artistKey(b) {
+			return true
+		}
+		if key := albumKey(a); key != "" && key == albumKey(b) {
+			return true
+		}
+		return false
+	}
+
+	artistCounts := map[string]int{}
+	for _, position := range remaining {
+		if key := artistKey(position); key != "" {
+			artistCounts[key]++
+		}
+	}
+
+	order := make([]int, 0, len(items))
+	conflicts := 0
+	for len(remaining) > 0 {
+		pick := -1
+		for i, position := range remaining {
+			if len(order) > 0 && conflict(order[len(order)-1], position) {
+				continue
+			}
+			key := artistKey(position)
+			if key != "" && artistCounts[key]*2 > len(remaining) {
+				pick = i
+				break
+			}
+			if pick < 0 {
+				pick = i
+			}
+		}
+		if pick < 0 {
+			pick = 0
+			conflicts++
+		}
+
+		position := remaining[pick]
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+		if key := artistKey(position); key != "" {
+			artistCounts[key]--
+		}
+		order = append(order, position)
+	}
+
+	return order, conflicts
+}
@@ -0,0 +1,131 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+)
+
+func listRecentChangesTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"list_recent_changes",
+		mcp.WithDescription(fmt.Sprintf("List the playlist changes made this session, newest first, with how undo_last would revert each. The last %d changes are kept until the server stops", maxJournalChanges)),
+		mcp.WithString("Playlist ID",
+			mcp.Description("Only list changes to this playlist"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  listRecentChangesBehaviour,
+	}
+}
+
+func listRecentChangesBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistID, err := optionalPlaylistID(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	var response string
+	count := 0
+	for _, entry := range recentChanges() {
+		if playlistID != "" && entry.playlistID != playlistID {
+			continue
+		}
+
+		response += fmt.Sprintf("%d. %s %s on %s", entry.id, entry.time.Format("2006-01-02 15:04:05"), entry.tool, entry.playlistLabel())
+		if count == 0 {
+			response += " (next to undo)"
+		}
+		response += "\n"
+		response += fmt.Sprintf("   %s\n", entry.summary)
+		response += fmt.Sprintf("   Undo: %s\n", entry.undoSummary)
+		count++
+	}
+
+	if count == 0 {
+		return mcp.NewToolResultText("No playlist changes were made this session."), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Recent playlist changes (%d), newest first:\n\n", count) + response), nil
+}
+
+func undoLastTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"undo_last",
+		mcp.WithDescription("Revert the most recent playlist change made this session: adds removed tracks back at their positions, removes added tracks, moves reordered tracks back, restores changed details or the previous cover, or unfollows a created playlist. Refuses if the playlist changed since, unless forced"),
+		mcp.WithString("Playlist ID",
+			mcp.Description("Undo the most recent change to this playlist instead of the most recent change overall"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Undo even if the playlist has changed since, e.g. in the Spotify app (default: false)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  undoLastBehaviour,
+	}
+}
+
+func undoLastBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistID, err := optionalPlaylistID(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	force, _ := tools.GetBoolParamFromRequest(request, "force")
+
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+	}
+
+	entry, ok := lastChange(playlistID)
+	if !ok {
+		return mcp.NewToolResultText("No playlist changes to undo in this session."), nil
+	}
+
+	state, err := playlistState(ctx, entry.playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.snapshotAfter != "" && state.SnapshotID != entry.snapshotAfter && !force {
+		response := fmt.Sprintf("%s has changed since %s (change %d), so undoing it could remove or move the wrong tracks.\n", entry.playlistLabel(), entry.tool, entry.id)
+		response += fmt.Sprintf("Snapshot ID after the change: %s\n", entry.snapshotAfter)
+		response += fmt.Sprintf("Current snapshot ID: %s\n", state.SnapshotID)
+		response += "Check the playlist, then set force to undo anyway.\n"
+		return mcp.NewToolResultText(response), nil
+	}
+
+	newSnapshotID, err := entry.undo(ctx, state.SnapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to undo %s: %w", entry.tool, err)
+	}
+
+	completeUndo(entry, newSnapshotID)
+
+	response := fmt.Sprintf("Successfully undid %s on %s!\n", entry.tool, entry.playlistLabel())
+	response += fmt.Sprintf("Change: %s\n", entry.summary)
+	response += fmt.Sprintf("Undo: %s\n", entry.undoSummary)
+	response += fmt.Sprintf("Playlist ID: %s\n", entry.playlistID)
+	if newSnapshotID != "" {
+		response += fmt.Sprintf("New snapshot ID: %s\n", newSnapshotID)
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func optionalPlaylistID(request mcp.CallToolRequest) (spotify.ID, error) {
+	value, _ := tools.GetParamFromRequest(request, "Playlist ID")
+	if strings.TrimSpace(value) == "" {
+		return "", nil
+	}
+	return parsePlaylistID(value)
+}
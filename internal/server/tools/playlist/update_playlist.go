@@ -0,0 +1,148 @@
+package playlist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zmb3/spotify/v2"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/server/tools"
+)
+
+func updatePlaylistTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"update_playlist",
+		mcp.WithDescription("Change the name, description, public or collaborative setting of a playlist you own. Only the given fields are changed"),
+		mcp.WithString("Playlist ID",
+			mcp.Required(),
+			mcp.Description("Spotify ID of the playlist"),
+		),
+		mcp.WithString("Name",
+			mcp.Description("New name of the playlist"),
+		),
+		mcp.WithString("Description",
+			mcp.Description("New description of the playlist, or an empty string to clear it"),
+		),
+		mcp.WithBoolean("Public",
+			mcp.Description("Whether the playlist should be public"),
+		),
+		mcp.WithBoolean("Collaborative",
+			mcp.Description("Whether the playlist should be collaborative. Collaborative playlists are always private"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  updatePlaylistBehaviour,
+	}
+}
+
+func updatePlaylistBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	playlistIDParam, err := tools.GetParamFromRequest(request, "Playlist ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist ID: %w", err)
+	}
+
+	playlistID, err := parsePlaylistID(playlistIDParam)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid playlist ID: %v", err)), nil
+	}
+
+	if !client.IsPlaybackAuthenticated() {
+		return mcp.NewToolResultError("Not authenticated with Spotify for playlist modification. Please use the spotify_login tool first."), nil
+	}
+
+	name, nameErr := tools.GetParamFromRequest(request, "Name")
+	nameSet := nameErr == nil
+	name = strings.TrimSpace(name)
+	if nameSet && name == "" {
+		return mcp.NewToolResultError("The playlist name can't be empty."), nil
+	}
+
+	description, descriptionErr := tools.GetParamFromRequest(request, "Description")
+	descriptionSet := descriptionErr == nil
+
+	isPublic, publicErr := tools.GetBoolParamFromRequest(request, "Public")
+	publicSet := publicErr == nil
+
+	isCollaborative, collaborativeErr := tools.GetBoolParamFromRequest(request, "Collaborative")
+	collaborativeSet := collaborativeErr == nil
+
+	if !nameSet && !descriptionSet && !publicSet && !collaborativeSet {
+		return mcp.NewToolResultText("Nothing to change. Provide at least one of Name, Description, Public or Collaborative."), nil
+	}
+
+	if collaborativeSet && isCollaborative && publicSet && isPublic {
+		return mcp.NewToolResultText("A collaborative playlist can't be public. Set Public to false or leave it out."), nil
+	}
+
+	spotifyClient := client.AuthenticatedSpotifyClient
+
+	previous, err := spotifyClient.GetPlaylist(ctx, playlistID, spotify.Fields("name,description,public,collaborative,snapshot_id"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist: %w", err)
+	}
+
+	if nameSet {
+		if err := spotifyClient.ChangePlaylistName(ctx, playlistID, name); err != nil {
+			return nil, fmt.Errorf("failed to change playlist name: %w", err)
+		}
+	}
+
+	if descriptionSet {
+		if err := spotifyClient.ChangePlaylistDescription(ctx, playlistID, description); err != nil {
+			return nil, fmt.Errorf("failed to change playlist description: %w", err)
+		}
+	}
+
+	// Turning collaboration off has to happen before a playlist can be made
+	// public, and turning it on makes the playlist private.
+	if collaborativeSet {
+		if err := client.ChangePlaylistCollaborative(ctx, playlistID, isCollaborative); err != nil {
+			return nil, fmt.Errorf("failed to change collaborative setting: %w", err)
+		}
+	}
+
+	if publicSet && !(collaborativeSet && isCollaborative) {
+		if err := spotifyClient.ChangePlaylistAccess(ctx, playlistID, isPublic); err != nil {
+			return nil, fmt.Errorf("failed to change playlist visibility: %w", err)
+		}
+	}
+
+	playlist, err := spotifyClient.GetPlaylist(ctx, playlistID, spotify.Fields("id,name,description,public,collaborative,external_urls,snapshot_id"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist: %w", err)
+	}
+
+	recordChange(journalEntry{
+		tool:           "update_playlist",
+		playlistID:     playlistID,
+		playlistName:   playlist.Name,
+		summary:        "Changed the playlist's details",
+		snapshotBefore: previous.SnapshotID,
+		snapshotAfter:  playlist.SnapshotID,
+		undoSummary:    fmt.Sprintf("Set the name back to %q and restore the description, public and collaborative settings", previous.Name),
+		undo: undoUpdate(playlistID, playlistDetails{
+			name:          previous.Name,
+			description:   previous.Description,
+			public:        previous.IsPublic,
+			collaborative: previous.Collaborative,
+		}),
+	})
+
+	response := fmt.Sprintf("Successfully updated playlist!\n\n")
+	response += fmt.Sprintf("Name: %s\n", playlist.Name)
+	response += fmt.Sprintf("ID: %s\n", playlist.ID)
+	response += fmt.Sprintf("Public: %t\n", playlist.IsPublic)
+	response += fmt.Sprintf("Collaborative: %t\n", playlist.Collaborative)
+
+	if playlist.Description != "" {
+		response += fmt.Sprintf("Description: %s\n", playlist.Description)
+	}
+
+	response += fmt.Sprintf("URL: %s\n", playlist.ExternalURLs["spotify"])
+
+	return mcp.NewToolResultText(response), nil
+}
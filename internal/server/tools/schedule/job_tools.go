@@ -0,0 +1,263 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"spotify-mcp/internal/scheduler"
+	"spotify-mcp/internal/server/tools"
+)
+
+// maxErrorsShown is how many recent failures list_jobs shows per job.
+const maxErrorsShown = 3
+
+func JobTools() []tools.ToolEntry {
+	return []tools.ToolEntry{
+		createJobTool(),
+		listJobsTool(),
+		pauseJobTool(),
+		deleteJobTool(),
+	}
+}
+
+func createJobTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"create_job",
+		mcp.WithDescription("Create a recurring job that runs on a cron schedule and is kept across restarts, e.g. a weekday wake-up playlist. Give either action or steps"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Short name for the job, e.g. \"Weekday alarm\""),
+		),
+		mcp.WithString("cron",
+			mcp.Required(),
+			mcp.Description("Five field cron expression in local time (minute hour day-of-month month day-of-week), e.g. \"30 6 * * MON-FRI\", or @hourly, @daily, @weekdays, @weekly, @monthly"),
+		),
+		mcp.WithString("action",
+			mcp.Description("Action to run: "+strings.Join(scheduler.ActionTypes, ", ")),
+		),
+		mcp.WithString("context_uri",
+			mcp.Description("Album, playlist or artist URI for the play_context action"),
+		),
+		mcp.WithNumber("volume",
+			mcp.Description("Volume level from 0 to 100 for set_volume, or the level to play at for play_context"),
+		),
+		mcp.WithNumber("fade_seconds",
+			mcp.Description("Fade duration in seconds for pause, fade_out and set_volume, or the fade-in for play_context with a volume"),
+		),
+		mcp.WithString("device",
+			mcp.Description("ID or name of the device to target (default: the active device when the job runs)"),
+		),
+		mcp.WithString("steps",
+			mcp.Description("Instead of action, a JSON array of tool calls to run in order, e.g. [{\"tool\":\"set_volume\",\"arguments\":{\"level\":20}},{\"tool\":\"play\",\"arguments\":{\"context_uri\":\"spotify:playlist:...\"}}]. Steps may call: "+strings.Join(scheduler.StepTools, ", ")),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  createJobBehaviour,
+	}
+}
+
+func createJobBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := tools.GetParamFromRequest(request, "name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get name parameter: %w", err)
+	}
+
+	cronExpression, err := tools.GetParamFromRequest(request, "cron")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cron parameter: %w", err)
+	}
+
+	steps, err := jobStepsFromRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	job, err := scheduler.CreateJob(name, cronExpression, steps)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not create the job: %v", err)), nil
+	}
+
+	response := "Job created!\n\n"
+	response += formatJob(job)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// jobStepsFromRequest reads either the action shorthand or explicit steps.
+func jobStepsFromRequest(request mcp.CallToolRequest) ([]scheduler.Step, error) {
+	actionType, _ := tools.GetParamFromRequest(request, "action")
+	stepsJSON, _ := tools.GetParamFromRequest(request, "steps")
+
+	hasAction := strings.TrimSpace(actionType) != ""
+	hasSteps := strings.TrimSpace(stepsJSON) != ""
+
+	switch {
+	case hasAction && hasSteps:
+		return nil, fmt.Errorf("give either action or steps, not both")
+	case hasAction:
+		action, err := actionFromRequest(request)
+		if err != nil {
+			return nil, err
+		}
+		return action.Steps(), nil
+	case hasSteps:
+		var steps []scheduler.Step
+		if err := json.Unmarshal([]byte(stepsJSON), &steps); err != nil {
+			return nil, fmt.Errorf("steps must be a JSON array of {\"tool\", \"arguments\"} objects: %v", err)
+		}
+		for i, step := range steps {
+			if strings.TrimSpace(step.Tool) == "" {
+				return nil, fmt.Errorf("step %d has no tool", i+1)
+			}
+		}
+		return steps, nil
+	default:
+		return nil, fmt.Errorf("give either action or steps")
+	}
+}
+
+func listJobsTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"list_jobs",
+		mcp.WithDescription("List recurring jobs with their next run, last run status and recent errors"),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  listJobsBehaviour,
+	}
+}
+
+func listJobsBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jobs := scheduler.ListJobs()
+
+	if len(jobs) == 0 {
+		return mcp.NewToolResultText("No jobs have been created."), nil
+	}
+
+	response := fmt.Sprintf("Jobs (%d):\n\n", len(jobs))
+	for _, job := range jobs {
+		response += formatJob(job) + "\n"
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func pauseJobTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"pause_job",
+		mcp.WithDescription("Pause or resume a recurring job"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("ID of the job, as shown by list_jobs"),
+		),
+		mcp.WithBoolean("paused",
+			mcp.Description("Set to false to resume the job (default: true)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  pauseJobBehaviour,
+	}
+}
+
+func pauseJobBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, err := tools.GetParamFromRequest(request, "id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get id parameter: %w", err)
+	}
+
+	paused, err := tools.GetBoolParamFromRequest(request, "paused")
+	if err != nil {
+		paused = true
+	}
+
+	job, err := scheduler.SetJobPaused(strings.TrimSpace(id), paused)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if paused {
+		return mcp.NewToolResultText(fmt.Sprintf("Paused %s (%s)", job.ID, job.Name)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Resumed %s (%s), next run at %s", job.ID, job.Name, job.NextRun.Format("2006-01-02 15:04"))), nil
+}
+
+func deleteJobTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"delete_job",
+		mcp.WithDescription("Delete a recurring job"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("ID of the job, as shown by list_jobs"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  deleteJobBehaviour,
+	}
+}
+
+func deleteJobBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, err := tools.GetParamFromRequest(request, "id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get id parameter: %w", err)
+	}
+
+	job, err := scheduler.DeleteJob(strings.TrimSpace(id))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Deleted %s (%s)", job.ID, job.Name)), nil
+}
+
+func formatJob(job scheduler.Job) string {
+	response := fmt.Sprintf("ID: %s\n", job.ID)
+	response += fmt.Sprintf("Name: %s\n", job.Name)
+	response += fmt.Sprintf("Schedule: %s\n", job.Cron)
+
+	var tools []string
+	for _, step := range job.Steps {
+		tools = append(tools, step.Tool)
+	}
+	response += fmt.Sprintf("Steps: %s\n", strings.Join(tools, " → "))
+
+	if job.Paused {
+		response += "Status: paused\n"
+	} else if !job.NextRun.IsZero() {
+		response += fmt.Sprintf("Next run: %s\n", job.NextRun.Format("2006-01-02 15:04"))
+	}
+
+	lastRun, ok := job.LastRun()
+	if !ok {
+		response += "Last run: never\n"
+		return response
+	}
+
+	response += fmt.Sprintf("Last run: %s (%s)\n", lastRun.StartedAt.Format("2006-01-02 15:04:05"), lastRun.Status)
+
+	var failures []scheduler.JobRun
+	for i := len(job.History) - 1; i >= 0 && len(failures) < maxErrorsShown; i-- {
+		if job.History[i].Error != "" {
+			failures = append(failures, job.History[i])
+		}
+	}
+
+	if len(failures) > 0 {
+		response += "Recent errors:\n"
+		for _, run := range failures {
+			response += fmt.Sprintf("  - %s: %s\n", run.StartedAt.Format("2006-01-02 15:04"), run.Error)
+		}
+	}
+
+	return response
+}
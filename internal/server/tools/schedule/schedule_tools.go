@@ -0,0 +1,231 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"spotify-mcp/internal/scheduler"
+	"spotify-mcp/internal/server/tools"
+	"spotify-mcp/internal/timeparse"
+)
+
+func ScheduleTools() []tools.ToolEntry {
+	return []tools.ToolEntry{
+		scheduleActionTool(),
+		listScheduledActionsTool(),
+		cancelScheduledActionTool(),
+	}
+}
+
+func scheduleActionTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"schedule_action",
+		mcp.WithDescription("Schedule a playback action, e.g. a sleep timer. Give exactly one of at, after or when"),
+		mcp.WithString("action",
+			mcp.Required(),
+			mcp.Description("Action to run: "+strings.Join(scheduler.ActionTypes, ", ")),
+		),
+		mcp.WithString("at",
+			mcp.Description("Wall-clock time to run at, e.g. \"23:30\", \"7am\" or \"2006-01-02 15:04\". A time of day that has passed means tomorrow"),
+		),
+		mcp.WithString("after",
+			mcp.Description("Delay before running, e.g. \"30m\", \"1h15m\" or \"45 minutes\""),
+		),
+		mcp.WithString("when",
+			mcp.Description("Run when playback reaches a point: end_of_track or end_of_context (the current album or playlist)"),
+		),
+		mcp.WithString("context_uri",
+			mcp.Description("Album, playlist or artist URI for the play_context action"),
+		),
+		mcp.WithNumber("volume",
+			mcp.Description("Volume level from 0 to 100 for set_volume, or the level to play at for play_context"),
+		),
+		mcp.WithNumber("fade_seconds",
+			mcp.Description("Fade duration in seconds for pause, fade_out (default: 30) and set_volume, or the fade-in for play_context with a volume"),
+		),
+		mcp.WithString("device",
+			mcp.Description("ID or name of the device to target (default: the active device when the action runs)"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  scheduleActionBehaviour,
+	}
+}
+
+func scheduleActionBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	action, err := actionFromRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	trigger, err := triggerFromRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	scheduled, err := scheduler.Schedule(ctx, action, trigger)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not schedule the action: %v", err)), nil
+	}
+
+	response := "Action scheduled!\n\n"
+	response += formatScheduledAction(scheduled)
+
+	return mcp.NewToolResultText(response), nil
+}
+
+// actionFromRequest reads the action and its options. It is shared with the
+// job tools so both accept the same arguments.
+func actionFromRequest(request mcp.CallToolRequest) (scheduler.Action, error) {
+	actionType, err := tools.GetParamFromRequest(request, "action")
+	if err != nil {
+		return scheduler.Action{}, fmt.Errorf("failed to get action parameter: %w", err)
+	}
+
+	action := scheduler.Action{Type: strings.ToLower(strings.TrimSpace(actionType))}
+	action.ContextURI, _ = tools.GetParamFromRequest(request, "context_uri")
+	action.Device, _ = tools.GetParamFromRequest(request, "device")
+	action.FadeSeconds, _ = tools.GetIntParamFromRequest(request, "fade_seconds")
+
+	if volume, err := tools.GetIntParamFromRequest(request, "volume"); err == nil {
+		action.Volume = &volume
+	}
+
+	if err := action.Validate(); err != nil {
+		return scheduler.Action{}, err
+	}
+
+	return action, nil
+}
+
+func triggerFromRequest(request mcp.CallToolRequest) (scheduler.Trigger, error) {
+	at, _ := tools.GetParamFromRequest(request, "at")
+	after, _ := tools.GetParamFromRequest(request, "after")
+	when, _ := tools.GetParamFromRequest(request, "when")
+
+	given := 0
+	for _, value := range []string{at, after, when} {
+		if strings.TrimSpace(value) != "" {
+			given++
+		}
+	}
+
+	if given != 1 {
+		return scheduler.Trigger{}, fmt.Errorf("give exactly one of at, after or when")
+	}
+
+	now := time.Now()
+	switch {
+	case strings.TrimSpace(at) != "":
+		atTime, err := timeparse.ParseFutureTime(at, now)
+		if err != nil {
+			return scheduler.Trigger{}, err
+		}
+		return scheduler.Trigger{Kind: scheduler.TriggerAt, At: atTime}, nil
+	case strings.TrimSpace(after) != "":
+		delay, err := timeparse.ParseDuration(after)
+		if err != nil {
+			return scheduler.Trigger{}, err
+		}
+		if delay <= 0 {
+			return scheduler.Trigger{}, fmt.Errorf("after must be a positive duration")
+		}
+		return scheduler.Trigger{Kind: scheduler.TriggerAt, At: now.Add(delay)}, nil
+	default:
+		switch strings.ToLower(strings.TrimSpace(when)) {
+		case scheduler.TriggerEndOfTrack:
+			return scheduler.Trigger{Kind: scheduler.TriggerEndOfTrack}, nil
+		case scheduler.TriggerEndOfContext:
+			return scheduler.Trigger{Kind: scheduler.TriggerEndOfContext}, nil
+		default:
+			return scheduler.Trigger{}, fmt.Errorf("invalid when %q, use end_of_track or end_of_context", when)
+		}
+	}
+}
+
+func listScheduledActionsTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"list_scheduled_actions",
+		mcp.WithDescription("List pending scheduled actions and recently finished ones"),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  listScheduledActionsBehaviour,
+	}
+}
+
+func listScheduledActionsBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pendingActions, finishedActions := scheduler.List()
+
+	if len(pendingActions) == 0 && len(finishedActions) == 0 {
+		return mcp.NewToolResultText("No actions are scheduled."), nil
+	}
+
+	response := fmt.Sprintf("Pending actions (%d):\n\n", len(pendingActions))
+	for _, scheduled := range pendingActions {
+		response += formatScheduledAction(scheduled) + "\n"
+	}
+
+	if len(finishedActions) > 0 {
+		response += fmt.Sprintf("Recently finished actions (%d):\n\n", len(finishedActions))
+		for _, scheduled := range finishedActions {
+			response += formatScheduledAction(scheduled) + "\n"
+		}
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func cancelScheduledActionTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"cancel_scheduled_action",
+		mcp.WithDescription("Cancel a pending scheduled action"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("ID of the action, as shown by list_scheduled_actions"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  cancelScheduledActionBehaviour,
+	}
+}
+
+func cancelScheduledActionBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, err := tools.GetParamFromRequest(request, "id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get id parameter: %w", err)
+	}
+
+	scheduled, err := scheduler.Cancel(strings.TrimSpace(id))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Cancelled %s: %s %s", scheduled.ID, scheduled.Action, scheduled.Trigger)), nil
+}
+
+func formatScheduledAction(scheduled scheduler.ScheduledAction) string {
+	response := fmt.Sprintf("ID: %s\n", scheduled.ID)
+	response += fmt.Sprintf("Action: %s\n", scheduled.Action)
+	response += fmt.Sprintf("Runs: %s\n", scheduled.Trigger)
+	if scheduled.Trigger.Kind == scheduler.TriggerAt && scheduled.Status == scheduler.StatusPending {
+		response += fmt.Sprintf("Time remaining: %s\n", time.Until(scheduled.Trigger.At).Round(time.Second))
+	}
+	response += fmt.Sprintf("Status: %s\n", scheduled.Status)
+	if !scheduled.FinishedAt.IsZero() {
+		response += fmt.Sprintf("Finished at: %s\n", scheduled.FinishedAt.Format("2006-01-02 15:04:05"))
+	}
+	if scheduled.Result != "" {
+		response += fmt.Sprintf("Result: %s\n", scheduled.Result)
+	}
+
+	return response
+}
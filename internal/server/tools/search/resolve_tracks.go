@@ -0,0 +1,135 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/matching"
+	"spotify-mcp/internal/resolve"
+	"spotify-mcp/internal/server/tools"
+)
+
+const (
+	// maxResolveInputs bounds the tracks resolved in one call.
+	maxResolveInputs = 100
+	// resolveAlternates is how many runners-up are listed per track.
+	resolveAlternates = 2
+)
+
+func resolveTracksTool() tools.ToolEntry {
+	toolDefinition := mcp.NewTool(
+		"resolve_tracks",
+		mcp.WithDescription("Find the Spotify track IDs for a list of songs in one call. Returns one compact row per song with the best track ID, its title and artists, a match score from 0 to 1 and up to two alternates, followed by all matched IDs in order. Prefer this over repeated simple_song_search calls"),
+		mcp.WithString("tracks",
+			mcp.Required(),
+			mcp.Description("One song per line as \"Title by Artist\" or \"Artist - Title\", optionally followed by \"| ISRC\". Spotify track links are accepted too"),
+		),
+	)
+
+	return tools.ToolEntry{
+		ToolDefinition: toolDefinition,
+		ToolBehaviour:  resolveTracksBehaviour,
+	}
+}
+
+func resolveTracksBehaviour(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	tracksParam, err := tools.GetParamFromRequest(request, "tracks")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks: %w", err)
+	}
+
+	var inputs []string
+	for _, line := range strings.Split(tracksParam, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			inputs = append(inputs, line)
+		}
+	}
+
+	if len(inputs) == 0 {
+		return mcp.NewToolResultText("No tracks provided."), nil
+	}
+	if len(inputs) > maxResolveInputs {
+		return mcp.NewToolResultError(fmt.Sprintf("Too many tracks provided. Maximum is %d per request.", maxResolveInputs)), nil
+	}
+
+	spotifyClient := client.SpotifyClient
+	if spotifyClient == nil {
+		return mcp.NewToolResultError("Spotify client not initialized. Please use the spotify_login tool first."), nil
+	}
+
+	queries := make([]resolve.Query, len(inputs))
+	for i, input := range inputs {
+		queries[i] = parseResolveInput(input)
+	}
+
+	results := resolve.ResolveAll(ctx, spotifyClient, queries, resolve.DefaultWorkers)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	response := ""
+	for i, result := range results {
+		response += fmt.Sprintf("%d. %s -> ", i+1, inputs[i])
+
+		best, found := result.Best()
+		switch {
+		case result.Err != nil:
+			response += fmt.Sprintf("lookup failed: %v\n", result.Err)
+			continue
+		case !found:
+			response += "no match\n"
+			continue
+		case !result.Resolved():
+			response += fmt.Sprintf("no confident match (closest: %s)\n", formatResolveMatch(best))
+			continue
+		}
+
+		ids = append(ids, string(best.ID))
+		response += fmt.Sprintf("%s | %s | %s | %.2f", best.ID, best.Title, strings.Join(best.Artists, ", "), best.Score)
+
+		var alternates []string
+		for _, match := range result.Matches[1:min(len(result.Matches), resolveAlternates+1)] {
+			alternates = append(alternates, formatResolveMatch(match))
+		}
+		if len(alternates) > 0 {
+			response += " | alternates: " + strings.Join(alternates, "; ")
+		}
+		response += "\n"
+	}
+
+	header := fmt.Sprintf("Resolved %d of %d tracks (ID | title | artists | score):\n\n", len(ids), len(inputs))
+	response = header + response
+	if len(ids) > 0 {
+		response += fmt.Sprintf("\nTrack IDs: %s\n", strings.Join(ids, ","))
+	}
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func formatResolveMatch(match resolve.Match) string {
+	return fmt.Sprintf("%s %s by %s (%.2f)", match.ID, match.Title, strings.Join(match.Artists, ", "), match.Score)
+}
+
+// parseResolveInput reads "Artist - Title", "Title by Artist" or a Spotify
+// link, with an optional "| ISRC" suffix.
+func parseResolveInput(input string) resolve.Query {
+	var query resolve.Query
+
+	text, isrc, _ := strings.Cut(input, "|")
+	isrc = strings.TrimSpace(isrc)
+	isrc = strings.TrimSpace(strings.TrimPrefix(strings.ToLower(isrc), "isrc:"))
+	query.ISRC = strings.ToUpper(isrc)
+
+	text = strings.TrimSpace(text)
+	if itemType, id, err := tools.ParseSpotifyURI(text, ""); err == nil && itemType == "track" {
+		query.ID = id
+		return query
+	}
+
+	query.Title, query.Artist = matching.SplitTitleArtist(text)
+	return query
+}
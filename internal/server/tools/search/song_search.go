@@ -16,6 +16,7 @@ const songNameParameter = "Song Name"
 func SongSearchTools() []tools.ToolEntry {
 	return []tools.ToolEntry{
 		simpleSongSearch(),
+		resolveTracksTool(),
 	}
 }
 
@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// spotifyItemTypes are the item types that can appear in Spotify URIs and URLs.
+var spotifyItemTypes = map[string]bool{
+	"track":    true,
+	"episode":  true,
+	"album":    true,
+	"artist":   true,
+	"playlist": true,
+	"show":     true,
+}
+
+// ParseSpotifyURI accepts a Spotify URI ("spotify:album:ID"), an
+// open.spotify.com URL or a bare ID, and returns the item type and ID. Bare IDs
+// are assumed to be of defaultType; if defaultType is empty they are rejected
+// because their type can not be known.
+func ParseSpotifyURI(value string, defaultType string) (string, spotify.ID, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", "", fmt.Errorf("empty Spotify ID")
+	}
+
+	var parts []string
+	switch {
+	case strings.HasPrefix(value, "spotify:"):
+		parts = strings.Split(strings.TrimPrefix(value, "spotify:"), ":")
+	case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid Spotify URL %q: %w", value, err)
+		}
+		host := strings.ToLower(parsed.Hostname())
+		if host != "spotify.com" && !strings.HasSuffix(host, ".spotify.com") {
+			return "", "", fmt.Errorf("%q is not a Spotify URL", value)
+		}
+		parts = strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	default:
+		if defaultType == "" {
+			return "", "", fmt.Errorf("%q is a bare ID, use a Spotify URI or URL so its type is known", value)
+		}
+		if !isSpotifyID(value) {
+			return "", "", fmt.Errorf("%q is not a valid Spotify ID", value)
+		}
+		return defaultType, spotify.ID(value), nil
+	}
+
+	// Take the last type/ID pair, which skips URL locale prefixes such as
+	// "intl-de" and legacy "spotify:user:NAME:playlist:ID" URIs.
+	for i := len(parts) - 2; i >= 0; i-- {
+		itemType := strings.ToLower(parts[i])
+		if spotifyItemTypes[itemType] && isSpotifyID(parts[i+1]) {
+			return itemType, spotify.ID(parts[i+1]), nil
+		}
+	}
+
+	return "", "", fmt.Errorf("could not find a Spotify item type and ID in %q", value)
+}
+
+// NormalizeSpotifyURI converts any value accepted by ParseSpotifyURI into a
+// canonical "spotify:TYPE:ID" URI.
+func NormalizeSpotifyURI(value string, defaultType string) (spotify.URI, string, error) {
+	itemType, id, err := ParseSpotifyURI(value, defaultType)
+	if err != nil {
+		return "", "", err
+	}
+
+	return ToSpotifyURI(itemType, id), itemType, nil
+}
+
+// ToSpotifyURI builds a canonical "spotify:TYPE:ID" URI.
+func ToSpotifyURI(itemType string, id spotify.ID) spotify.URI {
+	return spotify.URI(fmt.Sprintf("spotify:%s:%s", itemType, id))
+}
+
+func isSpotifyID(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for _, r := range value {
+		isAlphanumeric := (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isAlphanumeric {
+			return false
+		}
+	}
+
+	return true
+}
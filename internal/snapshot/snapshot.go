@@ -0,0 +1,198 @@
+// Package snapshot keeps local copies of playlists, so a playlist that was
+// emptied or vandalised can be compared with and restored to an earlier
+// state.
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"spotify-mcp/internal/storage"
+)
+
+const (
+	snapshotDir = "snapshots"
+	// maxPerPlaylist is how many snapshots are kept for each playlist; the
+	// oldest are deleted first.
+	maxPerPlaylist = 50
+	idLayout       = "20060102T150405Z"
+)
+
+var storeMutex sync.Mutex
+
+// Snapshot is a playlist's metadata and ordered items at one point in time.
+type Snapshot struct {
+	// ID identifies the snapshot locally; it is the UTC time it was taken.
+	ID         string `json:"id"`
+	PlaylistID string `json:"playlist_id"`
+	// SpotifySnapshotID is the playlist version Spotify reported.
+	SpotifySnapshotID string    `json:"spotify_snapshot_id"`
+	CreatedAt         time.Time `json:"created_at"`
+	Note              string    `json:"note,omitempty"`
+	Name              string    `json:"name"`
+	Description       string    `json:"description,omitempty"`
+	Owner             string    `json:"owner,omitempty"`
+	Public            bool      `json:"public"`
+	Collaborative     bool      `json:"collaborative"`
+	Items             []Item    `json:"items"`
+}
+
+// Item is one playlist entry.
+type Item struct {
+	URI        string   `json:"uri"`
+	Title      string   `json:"title"`
+	Artists    []string `json:"artists,omitempty"`
+	Album      string   `json:"album,omitempty"`
+	DurationMs int      `json:"duration_ms,omitempty"`
+	AddedAt    string   `json:"added_at,omitempty"`
+	AddedBy    string   `json:"added_by,omitempty"`
+	IsLocal    bool     `json:"is_local,omitempty"`
+}
+
+// Save stores a snapshot, setting its ID and creation time, and deletes the
+// playlist's oldest snapshots beyond the limit.
+func Save(snapshot *Snapshot) error {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+
+	existing, err := ids(snapshot.PlaylistID)
+	if err != nil {
+		return err
+	}
+
+	snapshot.CreatedAt = time.Now().UTC()
+	snapshot.ID = snapshot.CreatedAt.Format(idLayout)
+	for suffix := 2; contains(existing, snapshot.ID); suffix++ {
+		snapshot.ID = fmt.Sprintf("%s-%d", snapshot.CreatedAt.Format(idLayout), suffix)
+	}
+
+	if err := storage.WriteJSON(fileName(snapshot.PlaylistID, snapshot.ID), snapshot); err != nil {
+		return err
+	}
+
+	existing = append(existing, snapshot.ID)
+	for len(existing) > maxPerPlaylist {
+		path, err := storage.Path(fileName(snapshot.PlaylistID, existing[0]))
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete old snapshot %s: %w", existing[0], err)
+		}
+		existing = existing[1:]
+	}
+
+	return nil
+}
+
+// Load reads one snapshot of a playlist.
+func Load(playlistID, id string) (Snapshot, error) {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+
+	var snapshot Snapshot
+	found, err := storage.ReadJSON(fileName(playlistID, id), &snapshot)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if !found {
+		return Snapshot{}, fmt.Errorf("no snapshot %s for playlist %s", id, playlistID)
+	}
+
+	return snapshot, nil
+}
+
+// Latest reads the newest snapshot of a playlist.
+func Latest(playlistID string) (Snapshot, error) {
+	storeMutex.Lock()
+	existing, err := ids(playlistID)
+	storeMutex.Unlock()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if len(existing) == 0 {
+		return Snapshot{}, fmt.Errorf("no snapshots of playlist %s", playlistID)
+	}
+
+	return Load(playlistID, existing[len(existing)-1])
+}
+
+// List reads every snapshot of a playlist, oldest first.
+func List(playlistID string) ([]Snapshot, error) {
+	storeMutex.Lock()
+	existing, err := ids(playlistID)
+	storeMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]Snapshot, 0, len(existing))
+	for _, id := range existing {
+		snapshot, err := Load(playlistID, id)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// ids lists the IDs of a playlist's snapshots, oldest first.
+func ids(playlistID string) ([]string, error) {
+	dir, err := storage.Path(filepath.Join(snapshotDir, filepath.Base(playlistID)))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var result []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, ".json") {
+			result = append(result, strings.TrimSuffix(name, ".json"))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return lessID(result[i], result[j]) })
+
+	return result, nil
+}
+
+// lessID orders IDs by time, then by their collision suffix.
+func lessID(a, b string) bool {
+	aTime, aSuffix, _ := strings.Cut(a, "-")
+	bTime, bSuffix, _ := strings.Cut(b, "-")
+	if aTime != bTime {
+		return aTime < bTime
+	}
+	if len(aSuffix) != len(bSuffix) {
+		return len(aSuffix) < len(bSuffix)
+	}
+	return aSuffix < bSuffix
+}
+
+func fileName(playlistID, id string) string {
+	return filepath.Join(snapshotDir, filepath.Base(playlistID), filepath.Base(id)+".json")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
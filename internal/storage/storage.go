@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	dataDirEnv   = "SPOTIFY_MCP_DATA_DIR"
+	exportDirEnv = "SPOTIFY_EXPORT_DIR"
+)
+
+// DataDir returns the directory local state is kept in, creating it if needed.
+// It is SPOTIFY_MCP_DATA_DIR when set, otherwise spotify-mcp in the user's
+// config directory.
+func DataDir() (string, error) {
+	dir := os.Getenv(dataDirEnv)
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find a data directory, set %s: %w", dataDirEnv, err)
+		}
+		dir = filepath.Join(configDir, "spotify-mcp")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create data directory %s: %w", dir, err)
+	}
+
+	return dir, nil
+}
+
+// ExportDir returns the directory files such as playlist exports are written
+// to, creating it if needed. It is SPOTIFY_EXPORT_DIR when set, otherwise
+// exports in the data directory.
+func ExportDir() (string, error) {
+	dir := os.Getenv(exportDirEnv)
+	if dir == "" {
+		dataDir, err := DataDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(dataDir, "exports")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create export directory %s: %w", dir, err)
+	}
+
+	return dir, nil
+}
+
+// ExportPath returns the path of name inside the export directory. Names that
+// would lead outside it are rejected.
+func ExportPath(name string) (string, error) {
+	dir, err := ExportDir()
+	if err != nil {
+		return "", err
+	}
+
+	if filepath.IsAbs(name) || !filepath.IsLocal(name) {
+		return "", fmt.Errorf("%q must be a relative path inside the export directory", name)
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// OpenExportFile opens name inside the export directory for reading. Names
+// that would lead outside it are rejected, including through symlinks.
+func OpenExportFile(name string) (*os.File, error) {
+	dir, err := ExportDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if filepath.IsAbs(name) || !filepath.IsLocal(name) {
+		return nil, fmt.Errorf("%q must be a relative path inside the export directory", name)
+	}
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	return root.Open(name)
+}
+
+// Path returns the path of name inside the data directory.
+func Path(name string) (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// ReadJSON decodes the JSON file name in the data directory into v. It reports
+// false without an error if the file does not exist yet.
+func ReadJSON(name string, v interface{}) (bool, error) {
+	path, err := Path(name)
+	if err != nil {
+		return false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return true, nil
+}
+
+// WriteJSON encodes v to the JSON file name in the data directory. The file is
+// written to a temporary file first and renamed, so a crash never leaves it
+// half written.
+func WriteJSON(name string, v interface{}) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	return WriteFileAtomic(path, data)
+}
+
+// WriteFileAtomic writes data to path through a temporary file in the same
+// directory, then renames it into place.
+func WriteFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
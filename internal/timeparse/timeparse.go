@@ -0,0 +1,186 @@
+package timeparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// durationPartPattern matches one "<number> <unit>" pair such as "30 min" or "1.5h".
+	durationPartPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*([a-z]+)`)
+
+	durationUnits = map[string]time.Duration{
+		"ms":      time.Millisecond,
+		"s":       time.Second,
+		"sec":     time.Second,
+		"secs":    time.Second,
+		"second":  time.Second,
+		"seconds": time.Second,
+		"m":       time.Minute,
+		"min":     time.Minute,
+		"mins":    time.Minute,
+		"minute":  time.Minute,
+		"minutes": time.Minute,
+		"h":       time.Hour,
+		"hr":      time.Hour,
+		"hrs":     time.Hour,
+		"hour":    time.Hour,
+		"hours":   time.Hour,
+		"d":       24 * time.Hour,
+		"day":     24 * time.Hour,
+		"days":    24 * time.Hour,
+	}
+
+	// clockLayouts are the accepted formats for a time of day.
+	clockLayouts = []string{"15:04", "15:04:05", "3pm", "3:04pm", "3 pm", "3:04 pm"}
+
+	// dateTimeLayouts are the accepted formats for a date with an optional time.
+	dateTimeLayouts = []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+	}
+)
+
+// ParseDuration parses Go durations ("1h30m") as well as the more natural forms
+// "30 minutes", "1 hour 15 min" and "2 days".
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	if duration, err := time.ParseDuration(value); err == nil {
+		return duration, nil
+	}
+
+	matches := durationPartPattern.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("invalid duration %q, use a form such as 30m, 1h30m or \"45 minutes\"", value)
+	}
+
+	var total time.Duration
+	consumed := ""
+	for _, match := range matches {
+		number, err := strconv.ParseFloat(value[match[2]:match[3]], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+
+		unit, ok := durationUnits[value[match[4]:match[5]]]
+		if !ok {
+			return 0, fmt.Errorf("invalid duration %q: unknown unit %q", value, value[match[4]:match[5]])
+		}
+
+		total += time.Duration(number * float64(unit))
+		consumed += value[match[0]:match[1]]
+	}
+
+	// Reject input with leftover text, such as "30 minutes please".
+	leftover := strings.NewReplacer(" ", "", ",", "", "and", "").Replace(value)
+	if leftover != strings.ReplaceAll(consumed, " ", "") {
+		return 0, fmt.Errorf("invalid duration %q, use a form such as 30m, 1h30m or \"45 minutes\"", value)
+	}
+
+	return total, nil
+}
+
+// ParseTime parses a date and time (RFC 3339, "2006-01-02 15:04", ...) or a
+// time of day ("07:00", "3pm"), which is taken to be on the same day as now.
+// Times without a zone are in now's location.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty time")
+	}
+
+	for _, layout := range dateTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return parsed, nil
+		}
+	}
+
+	lowerValue := strings.ToLower(value)
+	for _, layout := range clockLayouts {
+		if parsed, err := time.ParseInLocation(layout, lowerValue, now.Location()); err == nil {
+			return time.Date(now.Year(), now.Month(), now.Day(), parsed.Hour(), parsed.Minute(), parsed.Second(), 0, now.Location()), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, use a time of day such as 07:00 or 3pm, or a date such as 2006-01-02 15:04", value)
+}
+
+// ParseFutureTime is like ParseTime, but a time of day that has already passed
+// today is moved to tomorrow.
+func ParseFutureTime(value string, now time.Time) (time.Time, error) {
+	parsed, err := ParseTime(value, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if parsed.Before(now) && isClockTime(value, now) {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+
+	return parsed, nil
+}
+
+func isClockTime(value string, now time.Time) bool {
+	lowerValue := strings.ToLower(strings.TrimSpace(value))
+	for _, layout := range clockLayouts {
+		if _, err := time.ParseInLocation(layout, lowerValue, now.Location()); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePastTime parses a point in the past: "now", "today", "yesterday", a
+// duration followed by "ago" ("20 minutes ago", "an hour ago"), or anything
+// ParseTime accepts. A time of day later than now is moved to yesterday.
+func ParsePastTime(value string, now time.Time) (time.Time, error) {
+	lowerValue := strings.ToLower(strings.TrimSpace(value))
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch lowerValue {
+	case "now":
+		return now, nil
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+
+	if ago, ok := strings.CutSuffix(lowerValue, " ago"); ok {
+		ago = strings.TrimSpace(ago)
+		for _, article := range []string{"an ", "a "} {
+			if rest, ok := strings.CutPrefix(ago, article); ok {
+				ago = "1 " + rest
+				break
+			}
+		}
+
+		duration, err := ParseDuration(ago)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(-duration), nil
+	}
+
+	parsed, err := ParseTime(value, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w, or a relative time such as \"1h ago\"", err)
+	}
+
+	if parsed.After(now) && isClockTime(value, now) {
+		parsed = parsed.AddDate(0, 0, -1)
+	}
+
+	return parsed, nil
+}
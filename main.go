@@ -2,15 +2,42 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"github.com/joho/godotenv"
+	"os"
 	"spotify-mcp/internal/client"
+	"spotify-mcp/internal/history"
 	"spotify-mcp/internal/server"
+	historyTools "spotify-mcp/internal/server/tools/history"
 )
 
 func main() {
 	godotenv.Load()
 
+	if len(os.Args) > 1 && os.Args[1] == "import-history" {
+		importHistory(os.Args[2:])
+		return
+	}
+
 	client.InstantiateSpotifyClient(context.Background())
 
 	server.StartMcpServer()
 }
+
+// importHistory imports streaming history exports from the command line:
+//
+//	spotify-mcp import-history path/to/Spotify\ Extended\ Streaming\ History
+func importHistory(paths []string) {
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: spotify-mcp import-history <file or folder>...")
+		os.Exit(2)
+	}
+
+	result, err := history.ImportStreamingHistory(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not import the streaming history: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(historyTools.FormatImportResult(result))
+}